@@ -0,0 +1,452 @@
+// Package alerts implements user defined rules that are evaluated against the server snapshots and log events,
+// triggering actions such as status bar messages, rcon commands or webhooks when they match.
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/notifier"
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+)
+
+const (
+	checkInterval = time.Second * 2
+	actionTimeout = time.Second * 10
+	// alertBuffer is the number of alerts buffered for display. Alerts are dropped when nothing is reading them,
+	// such as when running as a daemon.
+	alertBuffer = 10
+)
+
+var ErrInvalidRule = errors.New("invalid alert rule")
+
+// Executor runs rcon commands on a server.
+type Executor interface {
+	Exec(ctx context.Context, address string, command string) (string, error)
+}
+
+// SnapshotProvider provides the current state of all servers.
+type SnapshotProvider interface {
+	Snapshots() []state.Snapshot
+}
+
+// Alert is a single triggered rule.
+type Alert struct {
+	Rule     string
+	Trigger  config.AlertTrigger
+	Server   string
+	Hostname string
+	SteamID  steamid.SteamID
+	UserID   int
+	Name     string
+	Message  string
+	// Reason describes why the player matched the rule, such as the bot detector lists they were found in.
+	Reason    string
+	Actions   []config.AlertAction
+	CreatedOn time.Time
+	// resolved is true when the player is known to be on the server. Commands are not run for unresolved
+	// players as the user id would target the wrong player.
+	resolved bool
+}
+
+// Notify returns true if the alert should be shown to the user.
+func (a Alert) Notify() bool {
+	return a.Status() || a.Bell()
+}
+
+// Status returns true if the alert should be shown in the status bar.
+func (a Alert) Status() bool {
+	return slices.Contains(a.Actions, config.ActionStatus)
+}
+
+// Bell returns true if the terminal bell should be rung.
+func (a Alert) Bell() bool {
+	return slices.Contains(a.Actions, config.ActionBell)
+}
+
+// Title returns a short description of what triggered the alert.
+func (a Alert) Title() string {
+	switch a.Trigger {
+	case config.AlertVACBanned:
+		return "VAC banned player joined"
+	case config.AlertBDMatch:
+		return "Bot detector match joined"
+	case config.AlertMarked:
+		return "Marked player joined"
+	case config.AlertChat:
+		return "Chat message matched"
+	default:
+		return "Alert"
+	}
+}
+
+// String returns a human readable description of the alert.
+func (a Alert) String() string {
+	if a.Trigger == config.AlertChat {
+		return fmt.Sprintf("[%s] %s: %s", a.Rule, a.Name, a.Message)
+	}
+
+	return fmt.Sprintf("[%s] %s: %s (%s)", a.Rule, a.Title(), a.Name, a.SteamID.String())
+}
+
+type rule struct {
+	config.AlertRule
+	pattern  *regexp.Regexp
+	cooldown time.Duration
+	lastSent time.Time
+	webhook  *notifier.Webhook
+	// matched contains the players currently matching a join rule, so that each player only triggers the rule
+	// once while they remain on the server.
+	matched map[string]bool
+}
+
+// Engine evaluates the configured rules.
+type Engine struct {
+	rules  []*rule
+	links  []config.UserLink
+	exec   Executor
+	alerts chan Alert
+	mu     *sync.Mutex
+	// players are the players from the most recent snapshots, used to fill in the details of chat alerts.
+	players   map[string]state.Players
+	hostnames map[string]string
+}
+
+// New creates an engine for the rules. The links are included in webhook notifications.
+func New(rules []config.AlertRule, links []config.UserLink, exec Executor, client *http.Client) (*Engine, error) {
+	engine := &Engine{
+		links:     links,
+		exec:      exec,
+		alerts:    make(chan Alert, alertBuffer),
+		mu:        &sync.Mutex{},
+		players:   map[string]state.Players{},
+		hostnames: map[string]string{},
+	}
+
+	for _, alertRule := range rules {
+		compiled, errRule := newRule(alertRule, client)
+		if errRule != nil {
+			return nil, errRule
+		}
+
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+func newRule(alertRule config.AlertRule, client *http.Client) (*rule, error) {
+	compiled := &rule{
+		AlertRule: alertRule,
+		cooldown:  time.Duration(alertRule.CooldownSecs) * time.Second,
+		matched:   map[string]bool{},
+	}
+
+	switch alertRule.Trigger {
+	case config.AlertVACBanned, config.AlertBDMatch, config.AlertMarked:
+	case config.AlertChat:
+		pattern, errPattern := regexp.Compile(alertRule.Pattern)
+		if errPattern != nil {
+			return nil, errors.Join(errPattern, fmt.Errorf("%w: %s", ErrInvalidRule, alertRule.Name))
+		}
+
+		compiled.pattern = pattern
+	default:
+		return nil, fmt.Errorf("%w: %s: unknown trigger %q", ErrInvalidRule, alertRule.Name, alertRule.Trigger)
+	}
+
+	for _, action := range alertRule.Actions {
+		switch action {
+		case config.ActionStatus, config.ActionBell, config.ActionLog:
+		case config.ActionRCON:
+			if alertRule.Command == "" {
+				return nil, fmt.Errorf("%w: %s: rcon action requires a command", ErrInvalidRule, alertRule.Name)
+			}
+		case config.ActionWebhook:
+			if alertRule.WebhookURL == "" {
+				return nil, fmt.Errorf("%w: %s: webhook action requires a webhook_url", ErrInvalidRule, alertRule.Name)
+			}
+
+			format, errFormat := notifier.ParseFormat(alertRule.WebhookFormat)
+			if errFormat != nil {
+				return nil, errors.Join(errFormat, fmt.Errorf("%w: %s", ErrInvalidRule, alertRule.Name))
+			}
+
+			compiled.webhook = notifier.NewWebhook(client, alertRule.WebhookURL, format)
+		default:
+			return nil, fmt.Errorf("%w: %s: unknown action %q", ErrInvalidRule, alertRule.Name, action)
+		}
+	}
+
+	return compiled, nil
+}
+
+// Alerts returns the channel that alerts to be shown to the user are sent on.
+func (e *Engine) Alerts() <-chan Alert {
+	return e.alerts
+}
+
+// Start evaluates the rules against new snapshots and chat messages until the context is cancelled.
+func (e *Engine) Start(ctx context.Context, snapshots SnapshotProvider, router *events.Router) {
+	if len(e.rules) == 0 {
+		return
+	}
+
+	incoming := make(chan events.TypedEvent[events.MsgEvent], 10)
+	subscription := events.SubscribeTyped(router, incoming, events.SubscribeOpts{Name: "alerts"})
+	defer subscription.Cancel()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.OnSnapshots(ctx, snapshots.Snapshots())
+		case event := <-incoming:
+			e.OnEvent(ctx, event)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// OnSnapshots triggers the join rules for players that have started matching them.
+func (e *Engine) OnSnapshots(ctx context.Context, snapshots []state.Snapshot) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, snapshot := range snapshots {
+		e.players[snapshot.HostPort] = snapshot.Players
+		e.hostnames[snapshot.HostPort] = snapshot.Status.ServerName
+	}
+
+	for _, rule := range e.rules {
+		if rule.Trigger == config.AlertChat {
+			continue
+		}
+
+		current := map[string]bool{}
+
+		for _, snapshot := range snapshots {
+			for _, player := range snapshot.Players {
+				if !matchesPlayer(rule.Trigger, player) {
+					continue
+				}
+
+				key := snapshot.HostPort + "/" + player.SteamID.String()
+				if rule.matched[key] {
+					current[key] = true
+
+					continue
+				}
+
+				// Players suppressed by the cooldown are not recorded, so they are alerted once it has passed.
+				current[key] = e.trigger(ctx, rule, Alert{
+					Server:   snapshot.HostPort,
+					SteamID:  player.SteamID,
+					UserID:   player.UserID,
+					Name:     player.Name,
+					Reason:   matchReason(rule.Trigger, player),
+					resolved: true,
+				})
+			}
+		}
+
+		rule.matched = current
+	}
+}
+
+// OnEvent triggers the chat rules matching the message.
+func (e *Engine) OnEvent(ctx context.Context, event events.TypedEvent[events.MsgEvent]) {
+	msg := event.Data
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, rule := range e.rules {
+		if rule.Trigger != config.AlertChat || !rule.pattern.MatchString(msg.Message) {
+			continue
+		}
+
+		alert := Alert{
+			Server:  event.HostPort,
+			SteamID: msg.PlayerSID,
+			Name:    msg.Player,
+			Message: msg.Message,
+			Reason:  msg.Message,
+		}
+		if author, found := e.author(event.HostPort, msg); found {
+			alert.SteamID = author.SteamID
+			alert.UserID = author.UserID
+			alert.resolved = true
+		}
+
+		e.trigger(ctx, rule, alert)
+	}
+}
+
+// author finds the player that sent the message. Client mode messages only include the name, so these are only
+// resolved when exactly one player is using the name, as another player may be impersonating them.
+func (e *Engine) author(hostPort string, msg events.MsgEvent) (state.Player, bool) {
+	players := e.players[hostPort]
+	if msg.PlayerSID.Valid() {
+		for _, player := range players {
+			if player.SteamID.Equal(msg.PlayerSID) {
+				return player, true
+			}
+		}
+
+		return state.Player{}, false
+	}
+
+	matches := players.AllByName(msg.Player)
+	if len(matches) != 1 {
+		return state.Player{}, false
+	}
+
+	return matches[0], true
+}
+
+func matchesPlayer(trigger config.AlertTrigger, player state.Player) bool {
+	switch trigger { //nolint:exhaustive
+	case config.AlertVACBanned:
+		return player.Meta.NumberOfVacBans > 0
+	case config.AlertBDMatch:
+		return len(player.BDMatches) > 0
+	case config.AlertMarked:
+		return len(player.Mark.Tags) > 0
+	default:
+		return false
+	}
+}
+
+// matchReason describes why the player matches the trigger.
+func matchReason(trigger config.AlertTrigger, player state.Player) string {
+	switch trigger { //nolint:exhaustive
+	case config.AlertVACBanned:
+		return fmt.Sprintf("%d VAC ban(s), last ban %d days ago", player.Meta.NumberOfVacBans,
+			player.Meta.DaysSinceLastBan)
+	case config.AlertBDMatch:
+		var matches []string
+		for _, match := range player.BDMatches {
+			if len(match.Player.Attributes) > 0 {
+				matches = append(matches, fmt.Sprintf("%s (%s)", match.ListName,
+					strings.Join(match.Player.Attributes, ", ")))
+			} else {
+				matches = append(matches, match.ListName)
+			}
+		}
+
+		return "Found in: " + strings.Join(matches, ", ")
+	case config.AlertMarked:
+		if player.Mark.Reason != "" {
+			return fmt.Sprintf("%s: %s", strings.Join(player.Mark.Tags, ", "), player.Mark.Reason)
+		}
+
+		return strings.Join(player.Mark.Tags, ", ")
+	default:
+		return ""
+	}
+}
+
+// trigger runs the actions of the rule, unless the rule is still cooling down from a previous alert. Returns
+// false when the alert was suppressed by the cooldown.
+func (e *Engine) trigger(ctx context.Context, rule *rule, alert Alert) bool {
+	now := time.Now()
+	if !rule.lastSent.IsZero() && now.Sub(rule.lastSent) < rule.cooldown {
+		return false
+	}
+
+	rule.lastSent = now
+
+	alert.Rule = rule.Name
+	alert.Trigger = rule.Trigger
+	alert.Hostname = e.hostnames[alert.Server]
+	alert.Actions = rule.Actions
+	alert.CreatedOn = now
+
+	for _, action := range rule.Actions {
+		switch action {
+		case config.ActionLog:
+			slog.Warn("Alert triggered", slog.String("rule", alert.Rule), slog.String("server", alert.Server),
+				slog.String("steam_id", alert.SteamID.String()), slog.String("name", alert.Name),
+				slog.String("message", alert.Message))
+		case config.ActionRCON:
+			if !alert.resolved {
+				slog.Warn("Skipping alert command for unresolved player", slog.String("rule", alert.Rule),
+					slog.String("server", alert.Server), slog.String("name", alert.Name))
+
+				continue
+			}
+
+			go e.runCommand(ctx, rule.Command, alert)
+		case config.ActionWebhook:
+			go e.sendWebhook(ctx, rule.webhook, alert)
+		case config.ActionStatus, config.ActionBell:
+		}
+	}
+
+	if !alert.Notify() {
+		return true
+	}
+
+	select {
+	case e.alerts <- alert:
+	default:
+	}
+
+	return true
+}
+
+func (e *Engine) runCommand(ctx context.Context, command string, alert Alert) {
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+
+	command = strings.NewReplacer(
+		"{server}", alert.Server,
+		"{steam_id}", alert.SteamID.String(),
+		"{user_id}", strconv.Itoa(alert.UserID),
+		"{name}", tf.CommandValue(alert.Name),
+		"{message}", tf.CommandValue(alert.Message),
+	).Replace(command)
+
+	if _, err := e.exec.Exec(ctx, alert.Server, command); err != nil {
+		slog.Error("Failed to run alert command", slog.String("rule", alert.Rule),
+			slog.String("command", command), slog.String("error", err.Error()))
+	}
+}
+
+func (e *Engine) sendWebhook(ctx context.Context, webhook *notifier.Webhook, alert Alert) {
+	notification := notifier.Notification{
+		Title:     alert.Title(),
+		Player:    alert.Name,
+		SteamID:   alert.SteamID,
+		Server:    alert.Server,
+		Hostname:  alert.Hostname,
+		Reason:    alert.Reason,
+		CreatedOn: alert.CreatedOn,
+	}
+
+	if alert.SteamID.Valid() {
+		notification.Links = notifier.NewLinks(e.links, alert.SteamID)
+	}
+
+	if err := webhook.Send(ctx, notification); err != nil {
+		slog.Error("Failed to send alert webhook", slog.String("rule", alert.Rule),
+			slog.String("error", err.Error()))
+	}
+}
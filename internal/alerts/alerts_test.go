@@ -0,0 +1,238 @@
+package alerts_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/alerts"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/stretchr/testify/require"
+)
+
+const testAddress = "127.0.0.1:27015"
+
+type testExecutor struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func (e *testExecutor) Exec(_ context.Context, _ string, command string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.commands = append(e.commands, command)
+
+	return "", nil
+}
+
+func (e *testExecutor) Commands() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.commands
+}
+
+func receive(t *testing.T, engine *alerts.Engine) alerts.Alert {
+	t.Helper()
+
+	select {
+	case alert := <-engine.Alerts():
+		return alert
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for alert")
+
+		return alerts.Alert{}
+	}
+}
+
+func requireNoAlert(t *testing.T, engine *alerts.Engine) {
+	t.Helper()
+
+	select {
+	case alert := <-engine.Alerts():
+		t.Fatalf("unexpected alert: %s", alert.String())
+	default:
+	}
+}
+
+func TestJoinRule(t *testing.T) {
+	engine, errEngine := alerts.New([]config.AlertRule{
+		{Name: "vac", Trigger: config.AlertVACBanned, Actions: []config.AlertAction{config.ActionStatus, config.ActionBell}},
+	}, nil, &testExecutor{}, http.DefaultClient)
+	require.NoError(t, errEngine)
+
+	banned := state.Player{SteamID: steamid.New(76561197960265730), Name: "Cheater", UserID: 5}
+	banned.Meta.NumberOfVacBans = 1
+	clean := state.Player{SteamID: steamid.New(76561197960265731), Name: "Clean"}
+
+	snapshots := []state.Snapshot{{HostPort: testAddress, Players: state.Players{banned, clean}}}
+
+	engine.OnSnapshots(t.Context(), snapshots)
+	alert := receive(t, engine)
+	require.Equal(t, "vac", alert.Rule)
+	require.Equal(t, "Cheater", alert.Name)
+	require.True(t, alert.Bell())
+
+	// Only triggered once while the player remains.
+	engine.OnSnapshots(t.Context(), snapshots)
+	requireNoAlert(t, engine)
+
+	// Rejoining triggers the rule again.
+	engine.OnSnapshots(t.Context(), []state.Snapshot{{HostPort: testAddress, Players: state.Players{clean}}})
+	engine.OnSnapshots(t.Context(), snapshots)
+	require.Equal(t, "Cheater", receive(t, engine).Name)
+}
+
+func TestCooldown(t *testing.T) {
+	engine, errEngine := alerts.New([]config.AlertRule{
+		{Name: "marked", Trigger: config.AlertMarked, Actions: []config.AlertAction{config.ActionStatus}, CooldownSecs: 1},
+	}, nil, &testExecutor{}, http.DefaultClient)
+	require.NoError(t, errEngine)
+
+	first := state.Player{SteamID: steamid.New(76561197960265730), Name: "First", Mark: state.Mark{Tags: []string{"cheater"}}}
+	second := state.Player{SteamID: steamid.New(76561197960265731), Name: "Second", Mark: state.Mark{Tags: []string{"cheater"}}}
+
+	engine.OnSnapshots(t.Context(), []state.Snapshot{{HostPort: testAddress, Players: state.Players{first}}})
+	require.Equal(t, "First", receive(t, engine).Name)
+
+	both := []state.Snapshot{{HostPort: testAddress, Players: state.Players{first, second}}}
+	engine.OnSnapshots(t.Context(), both)
+	requireNoAlert(t, engine)
+
+	// The suppressed player is alerted once the cooldown has passed, while they are still on the server.
+	time.Sleep(time.Second)
+	engine.OnSnapshots(t.Context(), both)
+	require.Equal(t, "Second", receive(t, engine).Name)
+
+	engine.OnSnapshots(t.Context(), both)
+	requireNoAlert(t, engine)
+}
+
+func TestChatRule(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			received <- payload
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(webhook.Close)
+
+	executor := &testExecutor{}
+	engine, errEngine := alerts.New([]config.AlertRule{
+		{
+			Name:       "slurs",
+			Trigger:    config.AlertChat,
+			Pattern:    `(?i)\bbadword\b`,
+			Actions:    []config.AlertAction{config.ActionLog, config.ActionRCON, config.ActionWebhook},
+			Command:    "sm_gag #{user_id}",
+			WebhookURL: webhook.URL,
+		},
+	}, []config.UserLink{{Name: "demos.tf", URL: "https://demos.tf/profiles/%s"}}, executor, webhook.Client())
+	require.NoError(t, errEngine)
+
+	snapshot := state.Snapshot{HostPort: testAddress, Players: state.Players{
+		{SteamID: steamid.New(76561197960265730), Name: "Player One", UserID: 7},
+	}}
+	snapshot.Status.ServerName = "Test Server"
+	engine.OnSnapshots(t.Context(), []state.Snapshot{snapshot})
+
+	engine.OnEvent(t.Context(), events.TypedEvent[events.MsgEvent]{HostPort: testAddress, Data: events.MsgEvent{Player: "Player One", Message: "hello"}})
+	engine.OnEvent(t.Context(), events.TypedEvent[events.MsgEvent]{HostPort: testAddress, Data: events.MsgEvent{Player: "Player One", Message: "a BadWord"}})
+
+	require.Eventually(t, func() bool {
+		return len(executor.Commands()) == 1
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, "sm_gag #7", executor.Commands()[0])
+
+	select {
+	case payload := <-received:
+		require.Equal(t, "Chat message matched", payload["title"])
+		require.Equal(t, "Test Server", payload["hostname"])
+		require.Equal(t, "76561197960265730", payload["steam_id"])
+		require.Equal(t, "a BadWord", payload["reason"])
+		require.Len(t, payload["links"], 1)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for webhook")
+	}
+
+	// Neither the status or bell actions are set.
+	requireNoAlert(t, engine)
+}
+
+func TestChatRuleUnresolvedAuthor(t *testing.T) {
+	executor := &testExecutor{}
+	engine, errEngine := alerts.New([]config.AlertRule{
+		{
+			Name: "slurs", Trigger: config.AlertChat, Pattern: `(?i)\bbadword\b`,
+			Actions: []config.AlertAction{config.ActionStatus, config.ActionRCON}, Command: "sm_gag #{user_id}",
+		},
+	}, nil, executor, http.DefaultClient)
+	require.NoError(t, errEngine)
+
+	// The impersonator shares the name of the original player, so the author cannot be determined.
+	engine.OnSnapshots(t.Context(), []state.Snapshot{{HostPort: testAddress, Players: state.Players{
+		{SteamID: steamid.New(76561197960265730), Name: "Player One", UserID: 7},
+		{SteamID: steamid.New(76561197960265731), Name: "Player One", UserID: 8},
+	}}})
+
+	engine.OnEvent(t.Context(), events.TypedEvent[events.MsgEvent]{HostPort: testAddress, Data: events.MsgEvent{Player: "Player One", Message: "badword"}})
+	engine.OnEvent(t.Context(), events.TypedEvent[events.MsgEvent]{HostPort: testAddress, Data: events.MsgEvent{Player: "Nobody", Message: "badword"}})
+
+	// The alert is still shown, only the command is skipped.
+	for range 2 {
+		alert := receive(t, engine)
+		require.False(t, alert.SteamID.Valid())
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	require.Empty(t, executor.Commands())
+}
+
+func TestCommandInjection(t *testing.T) {
+	executor := &testExecutor{}
+	engine, errEngine := alerts.New([]config.AlertRule{
+		{
+			Name: "marked", Trigger: config.AlertMarked, Actions: []config.AlertAction{config.ActionRCON},
+			Command: `sm_say "{name} joined"`,
+		},
+	}, nil, executor, http.DefaultClient)
+	require.NoError(t, errEngine)
+
+	player := state.Player{
+		SteamID: steamid.New(76561197960265730), Name: "Evil\"; quit; \"\n", Mark: state.Mark{Tags: []string{"cheater"}},
+	}
+	engine.OnSnapshots(t.Context(), []state.Snapshot{{HostPort: testAddress, Players: state.Players{player}}})
+
+	require.Eventually(t, func() bool {
+		return len(executor.Commands()) == 1
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, `sm_say "Evil quit  joined"`, executor.Commands()[0])
+}
+
+func TestInvalidRules(t *testing.T) {
+	for _, rule := range []config.AlertRule{
+		{Name: "trigger", Trigger: "unknown"},
+		{Name: "pattern", Trigger: config.AlertChat, Pattern: "("},
+		{Name: "action", Trigger: config.AlertMarked, Actions: []config.AlertAction{"unknown"}},
+		{Name: "rcon", Trigger: config.AlertMarked, Actions: []config.AlertAction{config.ActionRCON}},
+		{Name: "webhook", Trigger: config.AlertMarked, Actions: []config.AlertAction{config.ActionWebhook}},
+		{
+			Name: "format", Trigger: config.AlertMarked, Actions: []config.AlertAction{config.ActionWebhook},
+			WebhookURL: "http://localhost", WebhookFormat: "unknown",
+		},
+	} {
+		_, errEngine := alerts.New([]config.AlertRule{rule}, nil, &testExecutor{}, http.DefaultClient)
+		require.ErrorIs(t, errEngine, alerts.ErrInvalidRule, rule.Name)
+	}
+}
@@ -0,0 +1,88 @@
+package bd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tfapi"
+)
+
+// SchemaURL is the location of the bot detector playerlist schema that exported lists conform to.
+const SchemaURL = "https://raw.githubusercontent.com/PazerOP/tf2_bot_detector/master/schemas/v3/playerlist.schema.json"
+
+// DefaultPlayerListName is the default file name of exported player lists.
+const DefaultPlayerListName = "playerlist.json"
+
+var ErrExport = errors.New("failed to export player list")
+
+// tagAttributes maps mark tags to the attributes allowed by the v3 playerlist schema. Tags without an
+// equivalent attribute, such as friend, are local only and are not exported.
+var tagAttributes = map[string]string{ //nolint:gochecknoglobals
+	"cheater":    "cheater",
+	"bot":        "cheater",
+	"suspicious": "suspicious",
+	"harasser":   "suspicious",
+	"exploiter":  "exploiter",
+	"racist":     "racist",
+}
+
+// ExportMarks writes all locally marked players to the writer as a bot detector playerlist. The tags of the
+// mark are mapped to the schema attributes, and the reason, if any, is used as the proof. Players whose only
+// tags are local only tags, such as friend, are not included.
+func ExportMarks(ctx context.Context, queries *store.Queries, info tfapi.BDFileInfo, writer io.Writer) (int, error) {
+	marks, errMarks := queries.ListMarks(ctx)
+	if errMarks != nil {
+		return 0, errors.Join(errMarks, ErrExport)
+	}
+
+	list := tfapi.BDSchema{
+		Schema:   SchemaURL,
+		FileInfo: info,
+		Players:  []tfapi.BDPlayer{},
+	}
+
+	for _, mark := range marks {
+		var attributes []string
+		for tag := range strings.SplitSeq(mark.Tags, ",") {
+			attribute, found := tagAttributes[strings.TrimSpace(tag)]
+			if found && !slices.Contains(attributes, attribute) {
+				attributes = append(attributes, attribute)
+			}
+		}
+
+		if len(attributes) == 0 {
+			continue
+		}
+
+		proof := []string{}
+		if mark.Note != "" {
+			proof = append(proof, mark.Note)
+		}
+
+		sid := steamid.New(mark.SteamID)
+		list.Players = append(list.Players, tfapi.BDPlayer{
+			Attributes: attributes,
+			LastSeen: tfapi.BDLastSeen{
+				PlayerName: mark.Name,
+				Time:       mark.UpdatedOn,
+			},
+			Proof:   proof,
+			Steamid: string(sid.Steam3()),
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(list); err != nil {
+		return 0, errors.Join(err, ErrExport)
+	}
+
+	return len(list.Players), nil
+}
@@ -0,0 +1,50 @@
+package bd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/leighmacdonald/tf-tui/internal/bd"
+	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tfapi"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestExportMarks(t *testing.T) {
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	queries := store.New(database)
+	for steamID, tags := range map[int64]string{
+		76561197960265729: "bot,cheater",
+		76561197960265730: "harasser,racist",
+		76561197960265731: "friend",
+	} {
+		require.NoError(t, queries.InsertPlayer(t.Context(), store.InsertPlayerParams{SteamID: steamID, Name: "Player"}))
+		require.NoError(t, queries.InsertMark(t.Context(), store.InsertMarkParams{SteamID: steamID, Tags: tags}))
+	}
+
+	var buf bytes.Buffer
+	count, errExport := bd.ExportMarks(t.Context(), queries, tfapi.BDFileInfo{Title: "test"}, &buf)
+	require.NoError(t, errExport)
+	require.Equal(t, 2, count)
+
+	var list tfapi.BDSchema
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &list))
+
+	attributes := map[string][]string{}
+	for _, player := range list.Players {
+		attributes[fmt.Sprint(player.Steamid)] = player.Attributes
+	}
+
+	// Only the attributes allowed by the schema are exported.
+	require.Equal(t, map[string][]string{
+		"[U:1:1]": {"cheater"},
+		"[U:1:2]": {"suspicious", "racist"},
+	}, attributes)
+}
@@ -3,20 +3,36 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"os"
 	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/alerts"
+	"github.com/leighmacdonald/tf-tui/internal/bd"
 	"github.com/leighmacdonald/tf-tui/internal/config"
 	"github.com/leighmacdonald/tf-tui/internal/network/upnp"
 	"github.com/leighmacdonald/tf-tui/internal/state"
 	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tf/console"
 	"github.com/leighmacdonald/tf-tui/internal/tf/events"
-	"github.com/leighmacdonald/tf-tui/internal/tf/rcon"
+	"github.com/leighmacdonald/tf-tui/internal/tfapi"
 	"github.com/leighmacdonald/tf-tui/internal/ui"
 )
 
+const (
+	// maxMatchHistory is the maximum number of matches shown in the match history.
+	maxMatchHistory = 100
+	// replaySeekStep is how far playback moves when seeking from the ui.
+	replaySeekStep = time.Second * 30
+)
+
+// replaySpeeds are the playback speeds cycled through from the ui.
+var replaySpeeds = []float64{1, 10, console.MaxSpeed} //nolint:gochecknoglobals
+
 type UI interface {
 	Send(msg tea.Msg)
 	Run() error
@@ -33,12 +49,15 @@ type App struct {
 	router        *events.Router
 	database      store.DBTX
 	parentCtx     chan any
+	alerts        *alerts.Engine
+	// replay is only set when replaying saved logs.
+	replay *console.Replay
 }
 
 // New returns a new application instance. To actually start the app you must call
 // Start().
 func New(conf config.Config, states *state.Manager, database store.DBTX, router *events.Router,
-	configUpdates chan config.Config,
+	configUpdates chan config.Config, alertEngine *alerts.Engine, replay *console.Replay,
 ) *App {
 
 	app := &App{
@@ -49,6 +68,8 @@ func New(conf config.Config, states *state.Manager, database store.DBTX, router
 		router:        router,
 		database:      database,
 		parentCtx:     make(chan any),
+		alerts:        alertEngine,
+		replay:        replay,
 	}
 
 	return app
@@ -75,6 +96,9 @@ func (app *App) Start(ctx context.Context, done <-chan any) {
 	// Start sending UI updates to the UI.
 	go app.uiSender(ctx)
 
+	// Start showing triggered alerts.
+	go app.alertUpdater(ctx)
+
 	if app.config.ServerModeEnabled && app.config.ServerUPNPEnabled {
 		external, internal := app.config.UPNPPortMapping()
 		go upnp.New(external, internal).Start(ctx)
@@ -86,6 +110,20 @@ func (app *App) Start(ctx context.Context, done <-chan any) {
 			switch req := req.(type) {
 			case ui.RCONCommand:
 				go app.onRCONCommand(ctx, req)
+			case ui.SaveNotesRequest:
+				go app.onSaveNotes(ctx, req)
+			case ui.MarkPlayerRequest:
+				go app.onMarkPlayer(ctx, req)
+			case ui.VoteKickRequest:
+				go app.onVoteKick(ctx, req)
+			case ui.ExportMarksRequest:
+				go app.onExportMarks(ctx)
+			case ui.MatchHistoryRequest:
+				go app.onMatchHistory(ctx)
+			case ui.MatchDetailRequest:
+				go app.onMatchDetail(ctx, req)
+			case ui.ReplayRequest:
+				app.onReplay(req)
 			}
 		case conf := <-app.configUpdates:
 			app.uiUpdates <- conf
@@ -98,24 +136,215 @@ func (app *App) Start(ctx context.Context, done <-chan any) {
 }
 
 func (app *App) onRCONCommand(ctx context.Context, cmd ui.RCONCommand) {
-	for _, server := range app.config.Servers {
-		if cmd.HostPort != server.Address {
-			continue
+	if _, err := app.state.Exec(ctx, cmd.HostPort, cmd.Command); err != nil {
+		slog.Error("Failed to exec rcon", slog.String("server", cmd.HostPort),
+			slog.String("cmd", cmd.Command), slog.String("error", err.Error()))
+	}
+}
+
+func (app *App) onSaveNotes(ctx context.Context, req ui.SaveNotesRequest) {
+	if err := app.state.SaveNotes(ctx, req.SteamID, req.Notes); err != nil {
+		slog.Error("Failed to save notes", slog.String("steam_id", req.SteamID.String()),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (app *App) onMarkPlayer(ctx context.Context, req ui.MarkPlayerRequest) {
+	if err := app.state.MarkPlayer(ctx, req.SteamID, req.Tags, req.Reason); err != nil {
+		slog.Error("Failed to mark player", slog.String("steam_id", req.SteamID.String()),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (app *App) onVoteKick(ctx context.Context, req ui.VoteKickRequest) {
+	if err := app.state.VoteKick(ctx, req.SteamID, req.UserID, req.Reason); err != nil {
+		slog.Error("Failed to call vote kick", slog.String("steam_id", req.SteamID.String()),
+			slog.String("error", err.Error()))
+		app.uiUpdates <- ui.StatusMsg{Message: "Failed to call vote kick", Err: true}
+	}
+}
+
+// onReplay controls the playback of replayed logs.
+func (app *App) onReplay(req ui.ReplayRequest) {
+	if app.replay == nil {
+		return
+	}
+
+	switch req.Action {
+	case ui.ReplayTogglePause:
+		app.replay.TogglePause()
+	case ui.ReplaySeekBack:
+		app.replay.Seek(-replaySeekStep)
+	case ui.ReplaySeekForward:
+		app.replay.Seek(replaySeekStep)
+	case ui.ReplayNextSpeed:
+		app.replay.SetSpeed(nextReplaySpeed(app.replay.Status().Speed))
+	}
+
+	app.sendReplayStatus()
+}
+
+// nextReplaySpeed cycles through the playback speeds available from the ui.
+func nextReplaySpeed(current float64) float64 {
+	for idx, speed := range replaySpeeds {
+		if speed == current {
+			return replaySpeeds[(idx+1)%len(replaySpeeds)]
+		}
+	}
+
+	return replaySpeeds[0]
+}
+
+func (app *App) sendReplayStatus() {
+	if app.replay == nil || app.ui == nil {
+		return
+	}
+
+	status := app.replay.Status()
+	app.ui.Send(ui.ReplayStatus{
+		Position: status.Position,
+		End:      status.End,
+		Speed:    status.Speed,
+		Paused:   status.Paused,
+		Done:     status.Done,
+	})
+}
+
+// sendRouterStats sends the delivery counters of the event router subscribers to the ui.
+func (app *App) sendRouterStats() {
+	stats := app.router.Stats()
+	subscribers := make([]ui.SubscriberStats, len(stats))
+	for idx, subscriber := range stats {
+		subscribers[idx] = ui.SubscriberStats{
+			Name:      subscriber.Name,
+			HostPort:  subscriber.HostPort,
+			Policy:    subscriber.Policy.String(),
+			Delivered: subscriber.Delivered,
+			Dropped:   subscriber.Dropped,
+		}
+	}
+
+	app.ui.Send(ui.RouterStats{Subscribers: subscribers})
+}
+
+// onExportMarks writes all marked players to a bot detector playerlist within the config directory.
+func (app *App) onExportMarks(ctx context.Context) {
+	outPath := config.Path(bd.DefaultPlayerListName)
+
+	count, errExport := app.exportMarks(ctx, outPath)
+	if errExport != nil {
+		slog.Error("Failed to export marks", slog.String("error", errExport.Error()))
+		app.uiUpdates <- ui.StatusMsg{Message: "Failed to export marks", Err: true}
+
+		return
+	}
+
+	app.uiUpdates <- ui.StatusMsg{Message: fmt.Sprintf("Exported %d players to %s", count, outPath)}
+}
+
+func (app *App) exportMarks(ctx context.Context, outPath string) (int, error) {
+	outFile, errCreate := os.Create(outPath)
+	if errCreate != nil {
+		return 0, errCreate
+	}
+
+	defer func() {
+		if err := outFile.Close(); err != nil {
+			slog.Error("Error closing export file", slog.String("error", err.Error()))
+		}
+	}()
+
+	info := tfapi.BDFileInfo{
+		Authors:     []string{},
+		Description: "Players marked with tf-tui",
+		Title:       "tf-tui player list",
+	}
+	if app.config.SteamID.Valid() {
+		info.Authors = append(info.Authors, app.config.SteamID.String())
+	}
+
+	return bd.ExportMarks(ctx, store.New(app.database), info, outFile)
+}
+
+// onMatchHistory loads the most recently recorded matches and sends them to the UI.
+func (app *App) onMatchHistory(ctx context.Context) {
+	rows, errRows := store.New(app.database).GetMatches(ctx, maxMatchHistory)
+	if errRows != nil {
+		slog.Error("Failed to load match history", slog.String("error", errRows.Error()))
+
+		return
+	}
+
+	history := make(ui.MatchHistory, len(rows))
+	for idx, row := range rows {
+		history[idx] = ui.MatchSummary{
+			MatchID:   row.MatchID,
+			Hostname:  row.Hostname,
+			Address:   row.Address,
+			MapName:   row.MapName,
+			Duration:  time.Duration(row.Duration) * time.Second,
+			Players:   int(row.PlayerCount),
+			CreatedOn: time.Unix(row.CreatedOn, 0),
 		}
+	}
+
+	app.uiUpdates <- history
+}
+
+// onMatchDetail loads the scoreboard and chat log of a single match and sends it to the UI.
+func (app *App) onMatchDetail(ctx context.Context, req ui.MatchDetailRequest) {
+	queries := store.New(app.database)
+
+	players, errPlayers := queries.GetMatchPlayers(ctx, req.MatchID)
+	if errPlayers != nil {
+		slog.Error("Failed to load match players", slog.String("error", errPlayers.Error()))
+
+		return
+	}
+
+	chat, errChat := queries.GetMatchChat(ctx, req.MatchID)
+	if errChat != nil {
+		slog.Error("Failed to load match chat", slog.String("error", errChat.Error()))
+
+		return
+	}
 
-		if _, err := rcon.New(server.Address, server.Password).Exec(ctx, cmd.Command, true); err != nil {
-			slog.Error("Failed to exec rcon", slog.String("server", cmd.HostPort),
-				slog.String("cmd", cmd.Command), slog.String("error", err.Error()))
+	detail := ui.MatchDetail{
+		MatchID: req.MatchID,
+		Players: make([]ui.MatchPlayer, len(players)),
+		Chat:    make([]ui.MatchChat, len(chat)),
+	}
+
+	for idx, player := range players {
+		detail.Players[idx] = ui.MatchPlayer{
+			SteamID:   steamid.New(player.SteamID),
+			Name:      player.Name,
+			Score:     int(player.Score),
+			Deaths:    int(player.Deaths),
+			Ping:      int(player.Ping),
+			Connected: time.Duration(player.Connected) * time.Second,
 		}
+	}
 
-		break
+	for idx, msg := range chat {
+		detail.Chat[idx] = ui.MatchChat{
+			SteamID:   steamid.New(msg.SteamID),
+			Name:      msg.Name,
+			Message:   msg.Message,
+			TeamOnly:  msg.TeamOnly == 1,
+			CreatedOn: time.Unix(msg.CreatedOn, 0),
+		}
 	}
+
+	app.uiUpdates <- detail
 }
 
 // logEventUpdater sends console log events to the UI for display.
 func (app *App) logEventUpdater(ctx context.Context) {
 	eventChan := make(chan events.Event, 10)
-	app.router.ListenFor("", eventChan, events.Any)
+	subscription := app.router.Subscribe(eventChan, events.SubscribeOpts{Name: "ui"})
+	defer subscription.Cancel()
+
 	for {
 		select {
 		case evt := <-eventChan:
@@ -126,6 +355,23 @@ func (app *App) logEventUpdater(ctx context.Context) {
 	}
 }
 
+// alertUpdater sends triggered alerts with the status or bell actions to the UI.
+func (app *App) alertUpdater(ctx context.Context) {
+	for {
+		select {
+		case alert := <-app.alerts.Alerts():
+			msg := ui.AlertMsg{Bell: alert.Bell()}
+			if alert.Status() {
+				msg.Message = alert.String()
+			}
+
+			app.uiUpdates <- msg
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // uiSender handles forwarding all events to the UI.
 func (app *App) uiSender(ctx context.Context) {
 	for {
@@ -160,6 +406,9 @@ func (app *App) updateUIState() {
 		return
 	}
 
+	app.sendReplayStatus()
+	app.sendRouterStats()
+
 	snapshots := app.state.Snapshots()
 	uiSnaps := make([]ui.Snapshot, len(snapshots))
 	for idx, snap := range snapshots {
@@ -169,6 +418,7 @@ func (app *App) updateUIState() {
 			HostPort:    snap.HostPort,
 			Status:      snap.Status,
 			CVars:       snap.CVars,
+			RCON:        snap.RCON,
 			Server: ui.Server{
 				Hostname: snap.Status.ServerName,
 				Map:      snap.Status.Map,
@@ -184,6 +434,7 @@ func (app *App) updateUIState() {
 				Deaths:                   player.Deaths,
 				Connected:                player.Connected,
 				Team:                     player.Team,
+				Class:                    player.Class,
 				Alive:                    player.Alive,
 				Valid:                    player.Valid,
 				UserID:                   player.UserID,
@@ -205,6 +456,14 @@ func (app *App) updateUIState() {
 				ProfileState:             player.Meta.ProfileState,
 				RealName:                 player.Meta.RealName,
 				TimeCreated:              player.Meta.TimeCreated,
+				Notes:                    player.Notes,
+				KillsAgainst:             player.KillsAgainst,
+				KilledBy:                 player.KilledBy,
+				MarkTags:                 player.Mark.Tags,
+				MarkReason:               player.Mark.Reason,
+				Impersonates:             player.Impersonates,
+				VoteKicks:                player.VoteKicks,
+				Pending:                  player.Pending,
 			})
 		}
 		uiSnaps[idx] = uiSnapsnot
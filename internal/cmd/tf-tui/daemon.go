@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"runtime"
+	"syscall"
+
+	"github.com/adrg/xdg"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/network/upnp"
+	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run without the terminal ui",
+	Long: `Run tf-tui as a long-running headless collector.
+
+Logs are ingested and matches are recorded to the database the same as when running the ui. This is mostly
+useful in server mode to monitor servers from a machine without a terminal. Logs are written to stderr.`,
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE:              daemon,
+}
+
+func daemon(cmd *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Make sure our config & data home exists.
+	if err := os.MkdirAll(path.Join(xdg.ConfigHome, config.ConfigDirName), 0o750); err != nil {
+		return errors.Join(err, errApp)
+	}
+
+	configUpdates := make(chan config.Config)
+	userConfig, errConfig := config.NewLoader(configUpdates).Read()
+	if errConfig != nil {
+		return errors.Join(errApp, errConfig)
+	}
+
+	level := slog.LevelInfo
+	if userConfig.Debug {
+		level = slog.LevelDebug
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	slog.Info("Starting tf-tui daemon", slog.String("version", BuildVersion),
+		slog.String("commit", BuildCommit), slog.String("date", BuildDate),
+		slog.String("go", runtime.Version()))
+
+	// There is no ui to apply config changes to, so just make sure the loader is never blocked.
+	go func() {
+		for range configUpdates {
+			slog.Warn("Config file changed, restart the daemon to apply changes")
+		}
+	}()
+
+	database, errDB := store.Open(ctx, config.Path(config.DefaultDBName), true)
+	if errDB != nil {
+		return errors.Join(errDB, errApp)
+	}
+
+	defer func() {
+		if err := database.Close(); err != nil {
+			slog.Error("Error closing database", slog.String("error", err.Error()))
+		}
+	}()
+
+	router := events.NewRouter()
+	states, errStates := newManager(ctx, userConfig, database, router)
+	if errStates != nil {
+		return errors.Join(errStates, errApp)
+	}
+
+	startHTTPAPI(ctx, userConfig, states, router)
+	startMetrics(ctx, userConfig, states)
+
+	// Only the log, rcon and webhook actions apply without a ui.
+	if _, errAlerts := startAlerts(ctx, userConfig, states, router); errAlerts != nil {
+		return errors.Join(errAlerts, errApp)
+	}
+
+	if userConfig.ServerModeEnabled && userConfig.ServerUPNPEnabled {
+		external, internal := userConfig.UPNPPortMapping()
+		go upnp.New(external, internal).Start(ctx)
+	}
+
+	errStart := make(chan error, 1)
+	go func() {
+		errStart <- states.Start(ctx, router)
+	}()
+
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutting down")
+	case err := <-errStart:
+		if err != nil {
+			closeManager(cancel, states)
+
+			return errors.Join(err, errApp)
+		}
+	}
+
+	closeManager(cancel, states)
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/leighmacdonald/tf-tui/internal/bd"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tfapi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportTitle     string
+	exportAuthors   []string
+	exportUpdateURL string
+	exportCmd       = &cobra.Command{
+		Use:   "export [output]",
+		Short: "Export marked players as a bot detector playerlist",
+		Long: `Export all locally marked players into a TF2 Bot Detector compatible playerlist.json file.
+
+The output defaults to playerlist.json in the current directory. Use - to write to stdout.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: exportMarks,
+	}
+)
+
+func exportMarks(cmd *cobra.Command, args []string) error {
+	output := bd.DefaultPlayerListName
+	if len(args) > 0 {
+		output = args[0]
+	}
+
+	database, errDB := store.Open(cmd.Context(), config.Path(config.DefaultDBName), true)
+	if errDB != nil {
+		return errors.Join(errDB, errApp)
+	}
+
+	defer func() {
+		if err := database.Close(); err != nil {
+			slog.Error("Error closing database", slog.String("error", err.Error()))
+		}
+	}()
+
+	var writer io.Writer = cmd.OutOrStdout()
+	if output != "-" {
+		outFile, errCreate := os.Create(output)
+		if errCreate != nil {
+			return errors.Join(errCreate, errApp)
+		}
+
+		defer func() {
+			if err := outFile.Close(); err != nil {
+				slog.Error("Error closing export file", slog.String("error", err.Error()))
+			}
+		}()
+
+		writer = outFile
+	}
+
+	info := tfapi.BDFileInfo{
+		Authors:     exportAuthors,
+		Description: "Players marked with tf-tui",
+		Title:       exportTitle,
+		UpdateUrl:   exportUpdateURL,
+	}
+	if info.Authors == nil {
+		info.Authors = []string{}
+	}
+
+	count, errExport := bd.ExportMarks(cmd.Context(), store.New(database), info, writer)
+	if errExport != nil {
+		return errors.Join(errExport, errApp)
+	}
+
+	if output != "-" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d players to %s\n", count, output) //nolint:errcheck
+	}
+
+	return nil
+}
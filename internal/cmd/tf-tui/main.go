@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"time"
@@ -16,10 +17,13 @@ import (
 	"github.com/adrg/xdg"
 	"github.com/charmbracelet/fang"
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/leighmacdonald/tf-tui/internal/alerts"
 	"github.com/leighmacdonald/tf-tui/internal/bd"
 	"github.com/leighmacdonald/tf-tui/internal/cache"
 	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/httpapi"
 	"github.com/leighmacdonald/tf-tui/internal/meta"
+	"github.com/leighmacdonald/tf-tui/internal/metrics"
 	"github.com/leighmacdonald/tf-tui/internal/state"
 	"github.com/leighmacdonald/tf-tui/internal/store"
 	"github.com/leighmacdonald/tf-tui/internal/tf/console"
@@ -54,11 +58,18 @@ var (
 
 var errApp = errors.New("application error")
 
+// shutdownTimeout is how long we wait for servers to be unregistered and the final matches to be recorded.
+const shutdownTimeout = time.Second * 15
+
 func main() {
 	configPath := config.Path(config.DefaultConfigName)
 	// cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", configPath, "Config file path")
-	rootCmd.AddCommand(versionCmd)
+	exportCmd.Flags().StringVar(&exportTitle, "title", "tf-tui player list", "Title of the list")
+	exportCmd.Flags().StringSliceVar(&exportAuthors, "author", nil, "Author(s) of the list")
+	exportCmd.Flags().StringVar(&exportUpdateURL, "update-url", "", "URL where an up to date copy of the list can be fetched")
+	replayCmd.Flags().StringVar(&replaySpeed, "speed", "1x", "Playback speed multiplier, eg: 1x, 10x or max")
+	rootCmd.AddCommand(versionCmd, exportCmd, daemonCmd, replayCmd)
 
 	if err := fang.Execute(context.Background(), rootCmd); err != nil {
 		slog.Error("Exited with error", slog.String("error", err.Error()))
@@ -76,6 +87,12 @@ func version(_ *cobra.Command, _ []string) {
 
 // run is the main entry point of tf-tui.
 func run(cmd *cobra.Command, _ []string) error {
+	return runUI(cmd, nil)
+}
+
+// runUI starts the ui along with all the background services. When replay is set, it is used as the log
+// source in place of the console.log or udp listener.
+func runUI(cmd *cobra.Command, replay func(userConfig config.Config) (*console.Replay, error)) error {
 	// If PROFILE is set, it will be used as the output file path for the profiler.
 	if len(os.Getenv("PROFILE")) > 0 {
 		f, err := os.Create(os.Getenv("PROFILE"))
@@ -118,21 +135,29 @@ func run(cmd *cobra.Command, _ []string) error {
 		slog.String("commit", BuildCommit), slog.String("date", BuildDate),
 		slog.String("go", runtime.Version()))
 
-	// Setup the filesystem cache, creating any necessary directories.
-	cache, errCache := cache.New()
-	if errCache != nil {
-		return errors.Join(errCache, errApp)
-	}
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
 
-	// Setup all the data sources responsible for fetching player data.
-	httpClient := &http.Client{Timeout: config.DefaultHTTPTimeout}
-	client, errClient := tfapi.NewClientWithResponses(userConfig.APIBaseURL, tfapi.WithHTTPClient(httpClient))
-	if errClient != nil {
-		return errors.Join(errClient, errApp)
+	// Setup the sqlite database system.
+	dbPath := config.Path(config.DefaultDBName)
+	if replay != nil {
+		// Replayed matches are recorded to a throwaway database so that they, and any lines replayed again
+		// after seeking, do not end up in the real match history or lifetime kill counts.
+		tempDir, errTemp := os.MkdirTemp("", "tf-tui-replay-")
+		if errTemp != nil {
+			return errors.Join(errTemp, errApp)
+		}
+
+		defer func() {
+			if err := os.RemoveAll(tempDir); err != nil {
+				slog.Error("Failed to remove replay database", slog.String("error", err.Error()))
+			}
+		}()
+
+		dbPath = filepath.Join(tempDir, config.DefaultDBName)
 	}
 
-	// Setup the sqlite database system.
-	database, errDB := store.Open(cmd.Context(), config.Path(config.DefaultDBName), true)
+	database, errDB := store.Open(ctx, dbPath, true)
 	if errDB != nil {
 		return errors.Join(errDB, errApp)
 	}
@@ -145,41 +170,133 @@ func run(cmd *cobra.Command, _ []string) error {
 
 	// Setup a log source depending on the operating mode.
 	router := events.NewRouter()
-	metaFetcher := meta.New(client, cache)
-	bdFetcher := bd.New(httpClient, userConfig.BDLists, cache)
-	// Download the lists.
-	go bdFetcher.Update(cmd.Context())
-
-	states, errStates := state.NewManager(router, userConfig, metaFetcher, bdFetcher, database)
+	states, errStates := newManager(ctx, userConfig, database, router)
 	if errStates != nil {
 		return errors.Join(errStates, errApp)
 	}
 
+	var logReplay *console.Replay
+	if replay != nil {
+		source, errReplay := replay(userConfig)
+		if errReplay != nil {
+			return errors.Join(errReplay, errApp)
+		}
+
+		states.SetReplay(source)
+		logReplay = source
+	}
+
+	startHTTPAPI(ctx, userConfig, states, router)
+	startMetrics(ctx, userConfig, states)
+
+	alertEngine, errAlerts := startAlerts(ctx, userConfig, states, router)
+	if errAlerts != nil {
+		return errors.Join(errAlerts, errApp)
+	}
+
 	if userConfig.Debug {
 		consoleDebug := console.NewDebug("testdata/console.log")
 		if errDebug := consoleDebug.Open(); errDebug != nil {
 			return errors.Join(errDebug, errApp)
 		}
-		go consoleDebug.Start(cmd.Context(), router)
+		go consoleDebug.Start(ctx, router)
 		defer func() {
-			if err := consoleDebug.Close(cmd.Context()); err != nil {
+			if err := consoleDebug.Close(ctx); err != nil {
 				slog.Error("Error closing console debug", slog.String("error", err.Error()))
 			}
 		}()
 	}
 
 	done := make(chan any)
-	app := New(userConfig, states, database, router, configUpdates)
+	app := New(userConfig, states, database, router, configUpdates, alertEngine, logReplay)
 
 	go func() {
-		if err := app.createUI(cmd.Context(), configLoader).Run(); err != nil {
+		if err := app.createUI(ctx, configLoader).Run(); err != nil {
 			slog.Error("Failed to run UI", slog.String("error", err.Error()))
 		}
 
 		done <- "🫃"
 	}()
 
-	app.Start(cmd.Context(), done)
+	app.Start(ctx, done)
+
+	closeManager(cancel, states)
 
 	return nil
 }
+
+// newManager sets up all the data sources responsible for fetching player data and the state manager
+// that uses them.
+func newManager(ctx context.Context, userConfig config.Config, database store.DBTX, router *events.Router,
+) (*state.Manager, error) {
+	// Setup the filesystem cache, creating any necessary directories.
+	cache, errCache := cache.New()
+	if errCache != nil {
+		return nil, errCache
+	}
+
+	httpClient := &http.Client{Timeout: config.DefaultHTTPTimeout}
+	client, errClient := tfapi.NewClientWithResponses(userConfig.APIBaseURL, tfapi.WithHTTPClient(httpClient))
+	if errClient != nil {
+		return nil, errClient
+	}
+
+	metaFetcher := meta.New(client, cache)
+	bdFetcher := bd.New(httpClient, userConfig.BDLists, cache)
+	// Download the lists.
+	go bdFetcher.Update(ctx)
+
+	return state.NewManager(router, userConfig, metaFetcher, bdFetcher, database)
+}
+
+// startHTTPAPI starts the local http api in the background when it is enabled.
+func startHTTPAPI(ctx context.Context, userConfig config.Config, states *state.Manager, router *events.Router) {
+	if !userConfig.HTTPEnabled {
+		return
+	}
+
+	go func() {
+		if err := httpapi.New(userConfig.HTTPListenAddress, states, router).Start(ctx); err != nil {
+			slog.Error("Failed to start http api", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// startAlerts starts evaluating the configured alert rules in the background.
+func startAlerts(ctx context.Context, userConfig config.Config, states *state.Manager, router *events.Router,
+) (*alerts.Engine, error) {
+	engine, errEngine := alerts.New(userConfig.Alerts, userConfig.Links, states, &http.Client{Timeout: config.DefaultHTTPTimeout})
+	if errEngine != nil {
+		return nil, errEngine
+	}
+
+	go engine.Start(ctx, states, router)
+
+	return engine, nil
+}
+
+// startMetrics starts the prometheus metrics server in the background when it is enabled in server mode.
+func startMetrics(ctx context.Context, userConfig config.Config, states *state.Manager) {
+	if !userConfig.ServerModeEnabled || !userConfig.MetricsEnabled {
+		return
+	}
+
+	go func() {
+		if err := metrics.New(userConfig.MetricsListenAddress, states).Start(ctx); err != nil {
+			slog.Error("Failed to start metrics server", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// closeManager stops the state manager and waits for it to finish shutting down. Close is given its own
+// timeout as the parent context is already cancelled at this point.
+func closeManager(cancel context.CancelFunc, states *state.Manager) {
+	cancel()
+
+	ctx, closeCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer closeCancel()
+
+	if err := states.Close(ctx); err != nil {
+		slog.Error("Failed to shutdown cleanly", slog.String("error", err.Error()))
+	}
+}
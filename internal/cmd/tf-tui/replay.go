@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/tf/console"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errReplayServer = errors.New("no server to attribute replayed lines to")
+
+	replaySpeed string
+	replayCmd   = &cobra.Command{
+		Use:   "replay [host:port=]file...",
+		Short: "Replay saved console.log, srcds log or packet capture files",
+		Long: `Replay saved console.log, srcds log or packet capture files through the ui, using the timestamps within
+the logs to reproduce the original timing between lines.
+
+Lines are attributed to the client address in client mode, or the first configured server in server mode.
+Prefix a file with host:port= to attribute it to a different server. Multiple files are merged in time order
+so that logs from multiple servers can be replayed together.
+
+Packet captures are recorded in server mode when server_capture_path is set. Packets with a log secret are
+attributed to the configured server with the same secret.
+
+While replaying, use p to pause, , and . to seek backwards and forwards and s to change the speed. The servers
+are not contacted while replaying, and replayed matches are only kept in a temporary match history that is
+discarded on exit.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: replay,
+	}
+)
+
+func replay(cmd *cobra.Command, args []string) error {
+	speed, errSpeed := console.ParseReplaySpeed(replaySpeed)
+	if errSpeed != nil {
+		return errors.Join(errSpeed, errApp)
+	}
+
+	return runUI(cmd, func(userConfig config.Config) (*console.Replay, error) {
+		files, errFiles := replayFiles(userConfig, args)
+		if errFiles != nil {
+			return nil, errFiles
+		}
+
+		// Packet captures identify servers by their log secret.
+		serverHostMap := map[int]string{}
+		for _, server := range userConfig.Servers {
+			if server.LogSecret > 0 {
+				serverHostMap[server.LogSecret] = server.Address
+			}
+		}
+
+		return console.NewReplay(speed, serverHostMap, files...), nil
+	})
+}
+
+// replayFiles parses the file arguments, which are optionally prefixed with the address of the server the
+// lines should be attributed to.
+func replayFiles(userConfig config.Config, args []string) ([]console.ReplayFile, error) {
+	defaultHostPort := userConfig.Client.Address
+	if userConfig.ServerModeEnabled {
+		defaultHostPort = ""
+		if len(userConfig.Servers) > 0 {
+			defaultHostPort = userConfig.Servers[0].Address
+		}
+	}
+
+	files := make([]console.ReplayFile, len(args))
+	for idx, arg := range args {
+		file := console.ReplayFile{HostPort: defaultHostPort, Path: arg}
+		if hostPort, path, found := strings.Cut(arg, "="); found {
+			file = console.ReplayFile{HostPort: hostPort, Path: path}
+		}
+
+		if file.HostPort == "" {
+			return nil, fmt.Errorf("%w: %s", errReplayServer, file.Path)
+		}
+
+		files[idx] = file
+	}
+
+	return files, nil
+}
@@ -61,6 +61,9 @@ type Config struct {
 	// ServerBindAddress is the address where the server should bind to.
 	ServerBindAddress string `mapstructure:"server_bind_address"`
 	ServerUPNPEnabled bool   `mapstructure:"server_upnp_enabled"`
+	// ServerCapturePath is an optional file that all raw log packets received are appended to, so they can
+	// be replayed later.
+	ServerCapturePath string `mapstructure:"server_capture_path"`
 	// BDLists contains a list of bot detector lists to use.
 	BDLists []UserList `mapstructure:"bd_lists"`
 	// Links can be used to provide additional links to websites in the overview panel.
@@ -69,6 +72,17 @@ type Config struct {
 	Servers []ServerConfig `mapstructure:"servers"`
 	// Client is the connect info for running in local client mode.
 	Client ServerConfig `mapstructure:"client"`
+	// HTTPEnabled enables the local HTTP API, exposing the current state as JSON and a stream of events.
+	HTTPEnabled bool `mapstructure:"http_enabled"`
+	// HTTPListenAddress is the address the HTTP API listens on. There is no authentication, so this should
+	// not be exposed publicly.
+	HTTPListenAddress string `mapstructure:"http_listen_address"`
+	// MetricsEnabled enables the prometheus metrics endpoint. This is only used in server mode.
+	MetricsEnabled bool `mapstructure:"metrics_enabled"`
+	// MetricsListenAddress is the address that metrics are served on at /metrics.
+	MetricsListenAddress string `mapstructure:"metrics_listen_address"`
+	// Alerts are rules that trigger actions when players matching them join, or chat messages match.
+	Alerts []AlertRule `mapstructure:"alerts"`
 }
 
 func (c Config) UPNPPortMapping() (uint16, uint16) {
@@ -127,6 +141,50 @@ func (u UserLink) Generate(steamID steamid.SteamID) string {
 	}
 }
 
+type AlertTrigger string
+
+const (
+	// AlertVACBanned triggers when a player with a VAC ban joins.
+	AlertVACBanned AlertTrigger = "vac_banned"
+	// AlertBDMatch triggers when a player found in one of the bot detector lists joins.
+	AlertBDMatch AlertTrigger = "bd_match"
+	// AlertMarked triggers when a player that has been marked joins.
+	AlertMarked AlertTrigger = "marked"
+	// AlertChat triggers when a chat message matches the pattern of the rule.
+	AlertChat AlertTrigger = "chat"
+)
+
+type AlertAction string
+
+const (
+	// ActionStatus shows the alert in the status bar.
+	ActionStatus AlertAction = "status"
+	// ActionBell rings the terminal bell.
+	ActionBell AlertAction = "bell"
+	// ActionLog writes the alert to the log.
+	ActionLog AlertAction = "log"
+	// ActionRCON runs the command of the rule on the server.
+	ActionRCON AlertAction = "rcon"
+	// ActionWebhook posts the alert to the webhook url of the rule.
+	ActionWebhook AlertAction = "webhook"
+)
+
+type AlertRule struct {
+	Name    string        `mapstructure:"name"`
+	Trigger AlertTrigger  `mapstructure:"trigger"`
+	Actions []AlertAction `mapstructure:"actions"`
+	// Pattern is the regular expression that chat messages are matched against for chat triggers.
+	Pattern string `mapstructure:"pattern"`
+	// Command is run on the server for rcon actions. The {server}, {steam_id}, {user_id}, {name} and
+	// {message} placeholders are replaced with the details of the alert.
+	Command    string `mapstructure:"command"`
+	WebhookURL string `mapstructure:"webhook_url"`
+	// WebhookFormat is the format of the webhook payload, either generic or discord. Defaults to generic.
+	WebhookFormat string `mapstructure:"webhook_format"`
+	// CooldownSecs is the minimum time between alerts for this rule.
+	CooldownSecs int `mapstructure:"cooldown_secs"`
+}
+
 type UserList struct {
 	URL       string `mapstructure:"url"`
 	Name      string `mapstructure:"name"`
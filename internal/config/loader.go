@@ -24,6 +24,7 @@ func NewLoader(changes chan<- Config) *Loader {
 	loader.SetDefault("server_log_address", "1.2.3.4:27115")
 	loader.SetDefault("server_upnp_enabled", false)
 	loader.SetDefault("server_bind_address", "1.2.3.4:27115")
+	loader.SetDefault("server_capture_path", "")
 	loader.SetDefault("api_base_url", "https://tf-api.roto.lol/")
 	loader.SetDefault("bd_lists", []map[string]string{})
 	loader.SetDefault("links", []map[string]string{
@@ -40,6 +41,11 @@ func NewLoader(changes chan<- Config) *Loader {
 			"log_secret": 0,
 		},
 	})
+	loader.SetDefault("http_enabled", false)
+	loader.SetDefault("http_listen_address", "127.0.0.1:8099")
+	loader.SetDefault("metrics_enabled", false)
+	loader.SetDefault("metrics_listen_address", "127.0.0.1:9099")
+	loader.SetDefault("alerts", []map[string]any{})
 	loader.SetDefault("debug", false)
 	loader.SetConfigName(DefaultConfigName)
 	loader.SetConfigType("yaml")
@@ -84,10 +90,16 @@ func (cl *Loader) Write(config Config) error {
 	cl.Set("server_mode_enabled", config.ServerModeEnabled)
 	cl.Set("server_log_address", config.ServerLogAddress)
 	cl.Set("server_bind_address", config.ServerBindAddress)
+	cl.Set("server_capture_path", config.ServerCapturePath)
 	cl.Set("api_base_url", config.APIBaseURL)
 	cl.Set("bd_lists", config.BDLists)
 	cl.Set("links", config.Links)
 	cl.Set("servers", config.Servers)
+	cl.Set("http_enabled", config.HTTPEnabled)
+	cl.Set("http_listen_address", config.HTTPListenAddress)
+	cl.Set("metrics_enabled", config.MetricsEnabled)
+	cl.Set("metrics_listen_address", config.MetricsListenAddress)
+	// Alerts are not editable within the ui, so they are written back unchanged from the file.
 
 	if err := cl.WriteConfig(); err != nil {
 		return errors.Join(err, errConfigWrite)
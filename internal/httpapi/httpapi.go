@@ -0,0 +1,309 @@
+// Package httpapi provides an optional local HTTP server exposing the current state as JSON, along with a
+// server-sent events stream of the parsed log events, so that overlays and bots can be built on top of tf-tui.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+)
+
+const (
+	// maxChatHistory is the number of recent chat messages kept in memory.
+	maxChatHistory = 250
+	// clientBuffer is the number of events buffered for each stream client. Events are dropped for clients
+	// that fall further behind than this.
+	clientBuffer      = 100
+	keepAliveInterval = time.Second * 15
+	readHeaderTimeout = time.Second * 10
+	shutdownTimeout   = time.Second * 5
+)
+
+var (
+	ErrListen    = errors.New("failed to start http api listener")
+	errNotFound  = errors.New("server not found")
+	errStreaming = errors.New("streaming unsupported")
+)
+
+// SnapshotProvider provides the current state of all servers.
+type SnapshotProvider interface {
+	Snapshots() []state.Snapshot
+}
+
+// API serves the current state and events over HTTP.
+type API struct {
+	listenAddress string
+	snapshots     SnapshotProvider
+	router        *events.Router
+	mux           *http.ServeMux
+	mu            *sync.RWMutex
+	chat          []ChatMessage
+	clients       map[chan Event]struct{}
+}
+
+func New(listenAddress string, snapshots SnapshotProvider, router *events.Router) *API {
+	api := &API{
+		listenAddress: listenAddress,
+		snapshots:     snapshots,
+		router:        router,
+		mux:           http.NewServeMux(),
+		mu:            &sync.RWMutex{},
+		clients:       map[chan Event]struct{}{},
+	}
+
+	api.mux.HandleFunc("GET /api/servers", api.onServers)
+	api.mux.HandleFunc("GET /api/servers/{address}", api.onServer)
+	api.mux.HandleFunc("GET /api/players", api.onPlayers)
+	api.mux.HandleFunc("GET /api/chat", api.onChat)
+	api.mux.HandleFunc("GET /api/events", api.onEvents)
+	api.mux.HandleFunc("GET /api/debug/subscribers", api.onSubscribers)
+
+	return api
+}
+
+// Handler returns the http handler serving all the api routes.
+func (a *API) Handler() http.Handler {
+	return a.mux
+}
+
+// Start begins listening for events and serving requests, blocking until the context is cancelled.
+func (a *API) Start(ctx context.Context) error {
+	listener, errListen := (&net.ListenConfig{}).Listen(ctx, "tcp", a.listenAddress)
+	if errListen != nil {
+		return errors.Join(errListen, ErrListen)
+	}
+
+	httpServer := &http.Server{
+		Handler:           a.mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	// Only subscribed once listening, as nothing would be receiving the events if the listener failed.
+	incoming := make(chan events.Event, clientBuffer)
+	subscription := a.router.Subscribe(incoming, events.SubscribeOpts{Name: "httpapi"})
+	defer subscription.Cancel()
+
+	go a.readEvents(ctx, incoming)
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shutdown http api", slog.String("error", err.Error()))
+		}
+	}()
+
+	slog.Info("Starting http api", slog.String("listen_addr", listener.Addr().String()))
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (a *API) readEvents(ctx context.Context, incoming <-chan events.Event) {
+	for {
+		select {
+		case event := <-incoming:
+			a.onEvent(event)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// onEvent records chat messages and sends the event to all connected stream clients.
+func (a *API) onEvent(event events.Event) {
+	resp := newEvent(event)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if msg, ok := event.Data.(events.MsgEvent); ok {
+		chat := ChatMessage{
+			Server:    event.HostPort,
+			Name:      msg.Player,
+			Message:   msg.Message,
+			TeamOnly:  msg.TeamOnly,
+			Dead:      msg.Dead,
+			CreatedOn: event.Timestamp,
+		}
+		if msg.PlayerSID.Valid() {
+			chat.SteamID = msg.PlayerSID.String()
+		}
+
+		a.chat = append(a.chat, chat)
+		if len(a.chat) > maxChatHistory {
+			a.chat = a.chat[len(a.chat)-maxChatHistory:]
+		}
+	}
+
+	for client := range a.clients {
+		select {
+		case client <- resp:
+		default:
+		}
+	}
+}
+
+func (a *API) onServers(w http.ResponseWriter, _ *http.Request) {
+	snapshots := a.snapshots.Snapshots()
+	servers := make([]Server, len(snapshots))
+	for idx, snapshot := range snapshots {
+		servers[idx] = newServer(snapshot)
+	}
+
+	writeJSON(w, http.StatusOK, servers)
+}
+
+func (a *API) onServer(w http.ResponseWriter, r *http.Request) {
+	address := r.PathValue("address")
+	for _, snapshot := range a.snapshots.Snapshots() {
+		if snapshot.HostPort == address {
+			writeJSON(w, http.StatusOK, newServer(snapshot))
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, errNotFound)
+}
+
+// onPlayers returns the players in all servers, or only the server provided with the server query parameter.
+func (a *API) onPlayers(w http.ResponseWriter, r *http.Request) {
+	server := r.URL.Query().Get("server")
+	players := []Player{}
+
+	for _, snapshot := range a.snapshots.Snapshots() {
+		if server != "" && snapshot.HostPort != server {
+			continue
+		}
+
+		for _, player := range snapshot.Players {
+			players = append(players, newPlayer(snapshot.HostPort, player))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, players)
+}
+
+// onChat returns the recent chat history, oldest first. Can be filtered using the server query parameter.
+func (a *API) onChat(w http.ResponseWriter, r *http.Request) {
+	server := r.URL.Query().Get("server")
+	messages := []ChatMessage{}
+
+	a.mu.RLock()
+	for _, msg := range a.chat {
+		if server == "" || msg.Server == server {
+			messages = append(messages, msg)
+		}
+	}
+	a.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// onSubscribers returns the delivery counters of every router subscriber, which is useful for finding
+// subscribers that are too slow to keep up with the events.
+func (a *API) onSubscribers(w http.ResponseWriter, _ *http.Request) {
+	stats := a.router.Stats()
+	subscribers := make([]Subscriber, len(stats))
+	for idx, subscriber := range stats {
+		subscribers[idx] = newSubscriber(subscriber)
+	}
+
+	writeJSON(w, http.StatusOK, subscribers)
+}
+
+// onEvents streams events to the client as server-sent events. The server and type query parameters can be
+// used to only receive matching events.
+func (a *API) onEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errStreaming)
+
+		return
+	}
+
+	var (
+		server    = r.URL.Query().Get("server")
+		eventType = r.URL.Query().Get("type")
+		client    = make(chan Event, clientBuffer)
+		keepAlive = time.NewTicker(keepAliveInterval)
+	)
+
+	defer keepAlive.Stop()
+
+	a.mu.Lock()
+	a.clients[client] = struct{}{}
+	a.mu.Unlock()
+
+	defer func() {
+		a.mu.Lock()
+		delete(a.clients, client)
+		a.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event := <-client:
+			if (server != "" && event.Server != server) || (eventType != "" && event.Type != eventType) {
+				continue
+			}
+
+			body, errMarshal := json.Marshal(event)
+			if errMarshal != nil {
+				slog.Error("Failed to encode event", slog.String("error", errMarshal.Error()))
+
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, body); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
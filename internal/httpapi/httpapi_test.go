@@ -0,0 +1,179 @@
+package httpapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/httpapi"
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/stretchr/testify/require"
+)
+
+const testAddress = "127.0.0.1:27015"
+
+type testSnapshots []state.Snapshot
+
+func (s testSnapshots) Snapshots() []state.Snapshot {
+	return s
+}
+
+func newTestAPI(t *testing.T) (*httptest.Server, *events.Router) {
+	t.Helper()
+
+	snapshot := state.Snapshot{
+		HostPort: testAddress,
+		Players: state.Players{
+			{SteamID: steamid.New(76561197960265730), Name: "Player One", Team: tf.RED, Ping: 50},
+		},
+	}
+	snapshot.Status.ServerName = "Test Server"
+
+	router := events.NewRouter()
+	api := httpapi.New("127.0.0.1:0", testSnapshots{snapshot}, router)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+
+	// Requests are made against the test server, this is only started to begin reading events.
+	go func() { _ = api.Start(ctx) }()
+
+	require.Eventually(t, func() bool { return len(router.Stats()) == 1 }, time.Second, time.Millisecond*10)
+
+	server := httptest.NewServer(api.Handler())
+	t.Cleanup(server.Close)
+
+	return server, router
+}
+
+func getJSON[T any](t *testing.T, url string) (T, int) {
+	t.Helper()
+
+	resp, errGet := http.Get(url) //nolint:noctx
+	require.NoError(t, errGet)
+
+	defer resp.Body.Close()
+
+	var value T
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&value))
+
+	return value, resp.StatusCode
+}
+
+func TestServers(t *testing.T) {
+	server, _ := newTestAPI(t)
+
+	servers, status := getJSON[[]httpapi.Server](t, server.URL+"/api/servers")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, servers, 1)
+	require.Equal(t, "Test Server", servers[0].Hostname)
+	require.Equal(t, 1, servers[0].PlayersCount)
+
+	single, status := getJSON[httpapi.Server](t, server.URL+"/api/servers/"+testAddress)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, testAddress, single.Address)
+
+	_, status = getJSON[map[string]string](t, server.URL+"/api/servers/1.2.3.4:27015")
+	require.Equal(t, http.StatusNotFound, status)
+}
+
+func TestPlayers(t *testing.T) {
+	server, _ := newTestAPI(t)
+
+	players, status := getJSON[[]httpapi.Player](t, server.URL+"/api/players")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, players, 1)
+	require.Equal(t, "Player One", players[0].Name)
+	require.Equal(t, "RED", players[0].Team)
+	require.Equal(t, "76561197960265730", players[0].SteamID)
+
+	players, _ = getJSON[[]httpapi.Player](t, server.URL+"/api/players?server=1.2.3.4:27015")
+	require.Empty(t, players)
+}
+
+func TestEventsAndChat(t *testing.T) {
+	server, router := newTestAPI(t)
+
+	req, errReq := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/events?type=msg", nil)
+	require.NoError(t, errReq)
+
+	resp, errResp := http.DefaultClient.Do(req)
+	require.NoError(t, errResp)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The stream client is registered before the headers are sent, so these will not be missed.
+	router.Send(testAddress, "Player One connected")
+	router.Send(testAddress, "Player One :  hello there")
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	var received []string
+	timeout := time.After(time.Second * 5)
+	for len(received) < 2 {
+		select {
+		case line := <-lines:
+			if line != "" {
+				received = append(received, line)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	require.Equal(t, "event: msg", received[0])
+	require.True(t, strings.HasPrefix(received[1], "data: "))
+
+	var event httpapi.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(received[1], "data: ")), &event))
+	require.Equal(t, testAddress, event.Server)
+
+	chat, _ := getJSON[[]httpapi.ChatMessage](t, server.URL+"/api/chat")
+	require.Len(t, chat, 1)
+	require.Equal(t, "hello there", chat[0].Message)
+}
+
+func TestSubscribers(t *testing.T) {
+	server, router := newTestAPI(t)
+
+	// Never read from, so every event is dropped.
+	router.Subscribe(make(chan events.Event), events.SubscribeOpts{Name: "slow", Types: []events.EventType{events.Msg}})
+	router.Send(testAddress, "Player One :  hello")
+
+	subscribers, status := getJSON[[]httpapi.Subscriber](t, server.URL+"/api/debug/subscribers")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, subscribers, 2)
+	require.Equal(t, "httpapi", subscribers[0].Name)
+	require.Equal(t, httpapi.Subscriber{
+		Name: "slow", Types: []string{"msg"}, Policy: "drop", Dropped: 1,
+	}, subscribers[1])
+}
+
+func TestStartListenError(t *testing.T) {
+	listener, errListen := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, errListen)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	router := events.NewRouter()
+	api := httpapi.New(listener.Addr().String(), testSnapshots{}, router)
+
+	require.ErrorIs(t, api.Start(t.Context()), httpapi.ErrListen)
+	require.Empty(t, router.Stats())
+}
@@ -0,0 +1,241 @@
+package httpapi
+
+import (
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+)
+
+// Server is the current state of a single server.
+type Server struct {
+	Address      string      `json:"address"`
+	Hostname     string      `json:"hostname"`
+	Map          string      `json:"map"`
+	Tags         []string    `json:"tags"`
+	Region       string      `json:"region"`
+	PlayersCount int         `json:"players_count"`
+	PlayersMax   int         `json:"players_max"`
+	Stats        ServerStats `json:"stats"`
+	RCON         RCONHealth  `json:"rcon"`
+}
+
+// ServerStats are the results of the stats command. These are only available in server mode.
+type ServerStats struct {
+	CPU        float32 `json:"cpu"`
+	InKBs      float32 `json:"in_kbs"`
+	OutKBs     float32 `json:"out_kbs"`
+	FPS        float32 `json:"fps"`
+	Uptime     int     `json:"uptime"`
+	MapChanges int     `json:"map_changes"`
+	Connects   int     `json:"connects"`
+}
+
+// RCONHealth is the state of the persistent rcon connection to the server.
+type RCONHealth struct {
+	Connected   bool      `json:"connected"`
+	Failures    int       `json:"failures"`
+	LastError   string    `json:"last_error"`
+	LatencyMs   int64     `json:"latency_ms"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// Player is a player currently in one of the servers.
+type Player struct {
+	Server       string   `json:"server"`
+	SteamID      string   `json:"steam_id"`
+	Name         string   `json:"name"`
+	UserID       int      `json:"user_id"`
+	Team         string   `json:"team"`
+	Class        string   `json:"class"`
+	Ping         int      `json:"ping"`
+	Loss         int      `json:"loss"`
+	Score        int      `json:"score"`
+	Deaths       int      `json:"deaths"`
+	Health       int      `json:"health"`
+	Alive        bool     `json:"alive"`
+	Connected    bool     `json:"connected"`
+	Time         int      `json:"time"`
+	KillsAgainst int      `json:"kills_against"`
+	KilledBy     int      `json:"killed_by"`
+	Notes        string   `json:"notes"`
+	MarkTags     []string `json:"mark_tags"`
+	MarkReason   string   `json:"mark_reason"`
+	Impersonates string   `json:"impersonates,omitempty"`
+	VoteKicks    int      `json:"vote_kicks"`
+	VACBans      int64    `json:"vac_bans"`
+	GameBans     int64    `json:"game_bans"`
+	// Pending is true for matchmaking lobby members that have not finished connecting.
+	Pending bool `json:"pending"`
+	// BDLists are the names of the bot detector lists the player was found in.
+	BDLists []string `json:"bd_lists"`
+}
+
+// ChatMessage is a chat message sent in one of the servers.
+type ChatMessage struct {
+	Server    string    `json:"server"`
+	SteamID   string    `json:"steam_id,omitempty"`
+	Name      string    `json:"name"`
+	Message   string    `json:"message"`
+	TeamOnly  bool      `json:"team_only"`
+	Dead      bool      `json:"dead"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+// Event is a parsed log event, as sent over the event stream.
+type Event struct {
+	Type      string    `json:"type"`
+	Server    string    `json:"server"`
+	Timestamp time.Time `json:"timestamp"`
+	Raw       string    `json:"raw"`
+	// Data contains the fields of the parsed event, which depend on the type.
+	Data any `json:"data,omitempty"`
+}
+
+// Subscriber is the delivery state of a single router subscriber.
+type Subscriber struct {
+	Name string `json:"name"`
+	// Server is empty for subscribers receiving events from all servers.
+	Server string `json:"server"`
+	// Types is empty for subscribers receiving all event types.
+	Types     []string `json:"types"`
+	Policy    string   `json:"policy"`
+	Delivered int64    `json:"delivered"`
+	Dropped   int64    `json:"dropped"`
+}
+
+type killData struct {
+	Player    string
+	PlayerSID steamid.SteamID
+	Victim    string
+	VictimSID steamid.SteamID
+	Weapon    string
+	Crit      bool
+	// The remaining fields are only set in server mode.
+	PlayerTeam     tf.Team
+	VictimTeam     tf.Team
+	CustomKill     string
+	PlayerPosition events.Position
+	VictimPosition events.Position
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newServer(snapshot state.Snapshot) Server {
+	return Server{
+		Address:      snapshot.HostPort,
+		Hostname:     snapshot.Status.ServerName,
+		Map:          snapshot.Status.Map,
+		Tags:         snapshot.Status.Tags,
+		Region:       snapshot.Region,
+		PlayersCount: len(snapshot.Players),
+		PlayersMax:   snapshot.Status.PlayersMax,
+		Stats: ServerStats{
+			CPU:        snapshot.Status.Stats.CPU,
+			InKBs:      snapshot.Status.Stats.InKBs,
+			OutKBs:     snapshot.Status.Stats.OutKBs,
+			FPS:        snapshot.Status.Stats.FPS,
+			Uptime:     snapshot.Status.Stats.Uptime,
+			MapChanges: snapshot.Status.Stats.MapChanges,
+			Connects:   snapshot.Status.Stats.Connects,
+		},
+		RCON: RCONHealth{
+			Connected:   snapshot.RCON.Connected,
+			Failures:    snapshot.RCON.Failures,
+			LastError:   snapshot.RCON.LastError,
+			LatencyMs:   snapshot.RCON.Latency.Milliseconds(),
+			NextAttempt: snapshot.RCON.NextAttempt,
+		},
+	}
+}
+
+func newPlayer(server string, player state.Player) Player {
+	resp := Player{
+		Server:       server,
+		SteamID:      player.SteamID.String(),
+		Name:         player.Name,
+		UserID:       player.UserID,
+		Team:         player.Team.String(),
+		Class:        player.Class.String(),
+		Ping:         player.Ping,
+		Loss:         player.Loss,
+		Score:        player.Score,
+		Deaths:       player.Deaths,
+		Health:       player.Health,
+		Alive:        player.Alive,
+		Connected:    player.Connected,
+		Time:         player.Time,
+		KillsAgainst: player.KillsAgainst,
+		KilledBy:     player.KilledBy,
+		Notes:        player.Notes,
+		MarkTags:     player.Mark.Tags,
+		MarkReason:   player.Mark.Reason,
+		VoteKicks:    player.VoteKicks,
+		Pending:      player.Pending,
+		VACBans:      player.Meta.NumberOfVacBans,
+		GameBans:     player.Meta.NumberOfGameBans,
+		BDLists:      []string{},
+	}
+
+	if player.Impersonates.Valid() {
+		resp.Impersonates = player.Impersonates.String()
+	}
+
+	for _, match := range player.BDMatches {
+		resp.BDLists = append(resp.BDLists, match.ListName)
+	}
+
+	return resp
+}
+
+func newEvent(event events.Event) Event {
+	resp := Event{
+		Type:      event.Type.String(),
+		Server:    event.HostPort,
+		Timestamp: event.Timestamp,
+		Raw:       event.Raw,
+	}
+
+	// Kill events embed the parent event which would otherwise be duplicated.
+	if kill, ok := event.Data.(events.KillEvent); ok {
+		resp.Data = killData{
+			Player:    kill.Player,
+			PlayerSID: kill.PlayerSID,
+			Victim:    kill.Victim,
+			VictimSID: kill.VictimSID,
+			Weapon:    kill.Weapon,
+			Crit:      kill.Crit,
+
+			PlayerTeam:     kill.PlayerTeam,
+			VictimTeam:     kill.VictimTeam,
+			CustomKill:     kill.CustomKill,
+			PlayerPosition: kill.PlayerPosition,
+			VictimPosition: kill.VictimPosition,
+		}
+	} else {
+		resp.Data = event.Data
+	}
+
+	return resp
+}
+
+func newSubscriber(stats events.SubscriptionStats) Subscriber {
+	subscriber := Subscriber{
+		Name:      stats.Name,
+		Server:    stats.HostPort,
+		Types:     make([]string, len(stats.Types)),
+		Policy:    stats.Policy.String(),
+		Delivered: stats.Delivered,
+		Dropped:   stats.Dropped,
+	}
+
+	for idx, eventType := range stats.Types {
+		subscriber.Types[idx] = eventType.String()
+	}
+
+	return subscriber
+}
@@ -0,0 +1,198 @@
+// Package metrics serves the state of the servers in the prometheus text exposition format so that they can
+// be scraped and graphed. This is only used in server mode.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/state"
+)
+
+const (
+	contentType       = "text/plain; version=0.0.4; charset=utf-8"
+	readHeaderTimeout = time.Second * 10
+	shutdownTimeout   = time.Second * 5
+)
+
+var ErrListen = errors.New("failed to start metrics listener")
+
+// Source provides the current state of all servers.
+type Source interface {
+	Snapshots() []state.Snapshot
+	LogPacketCounts() map[string]int64
+}
+
+type metricType string
+
+const (
+	gauge   metricType = "gauge"
+	counter metricType = "counter"
+)
+
+// metric describes a single value collected from each server snapshot.
+type metric struct {
+	name  string
+	help  string
+	kind  metricType
+	value func(snapshot state.Snapshot) float64
+}
+
+var serverMetrics = []metric{
+	{"tftui_server_cpu_percent", "CPU usage reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float32Value(s.Status.Stats.CPU) }},
+	{"tftui_server_in_kbps", "Incoming network traffic in KB/s reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float32Value(s.Status.Stats.InKBs) }},
+	{"tftui_server_out_kbps", "Outgoing network traffic in KB/s reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float32Value(s.Status.Stats.OutKBs) }},
+	{"tftui_server_fps", "Server frame rate reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float32Value(s.Status.Stats.FPS) }},
+	{"tftui_server_uptime_minutes", "Server uptime in minutes reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float64(s.Status.Stats.Uptime) }},
+	{"tftui_server_map_changes", "Number of map changes reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float64(s.Status.Stats.MapChanges) }},
+	{"tftui_server_players", "Number of players reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float64(s.Status.Stats.Players) }},
+	{"tftui_server_players_max", "Maximum number of players.", gauge,
+		func(s state.Snapshot) float64 { return float64(s.Status.PlayersMax) }},
+	{"tftui_server_connects", "Number of connections reported by the stats command.", gauge,
+		func(s state.Snapshot) float64 { return float64(s.Status.Stats.Connects) }},
+	{"tftui_server_log_events_total", "Total number of log events received.", counter,
+		func(s state.Snapshot) float64 { return float64(s.EventCount) }},
+	{"tftui_rcon_connected", "Whether the rcon connection is currently established.", gauge,
+		func(s state.Snapshot) float64 { return boolValue(s.RCON.Connected) }},
+	{"tftui_rcon_latency_seconds", "Round trip time of the last successful rcon command.", gauge,
+		func(s state.Snapshot) float64 { return s.RCON.Latency.Seconds() }},
+	{"tftui_rcon_failures", "Number of consecutive failed rcon connection attempts.", gauge,
+		func(s state.Snapshot) float64 { return float64(s.RCON.Failures) }},
+	{"tftui_rcon_commands_total", "Total number of rcon commands executed.", counter,
+		func(s state.Snapshot) float64 { return float64(s.RCON.Commands) }},
+	{"tftui_rcon_errors_total", "Total number of rcon commands that failed.", counter,
+		func(s state.Snapshot) float64 { return float64(s.RCON.Errors) }},
+}
+
+// Exporter serves the metrics over HTTP.
+type Exporter struct {
+	listenAddress string
+	source        Source
+}
+
+func New(listenAddress string, source Source) *Exporter {
+	return &Exporter{listenAddress: listenAddress, source: source}
+}
+
+// Handler returns the http handler serving the /metrics route.
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", e.onMetrics)
+
+	return mux
+}
+
+// Start serves the metrics, blocking until the context is cancelled.
+func (e *Exporter) Start(ctx context.Context) error {
+	listener, errListen := (&net.ListenConfig{}).Listen(ctx, "tcp", e.listenAddress)
+	if errListen != nil {
+		return errors.Join(errListen, ErrListen)
+	}
+
+	httpServer := &http.Server{
+		Handler:           e.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shutdown metrics server", slog.String("error", err.Error()))
+		}
+	}()
+
+	slog.Info("Starting metrics server", slog.String("listen_addr", listener.Addr().String()))
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (e *Exporter) onMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+
+	if _, err := w.Write(e.render()); err != nil {
+		slog.Error("Failed to write metrics", slog.String("error", err.Error()))
+	}
+}
+
+// render writes all metrics in the text exposition format.
+func (e *Exporter) render() []byte {
+	var (
+		buf       bytes.Buffer
+		snapshots = e.source.Snapshots()
+	)
+
+	for _, metric := range serverMetrics {
+		writeHeader(&buf, metric.name, metric.help, metric.kind)
+
+		for _, snapshot := range snapshots {
+			writeValue(&buf, metric.name, snapshot.HostPort, metric.value(snapshot))
+		}
+	}
+
+	// Packets are counted by the log listener rather than by each server, so these are reported separately.
+	// Packets without a log secret cannot be attributed to a server and have an empty server label.
+	counts := e.source.LogPacketCounts()
+
+	writeHeader(&buf, "tftui_log_packets_total", "Total number of log packets received.", counter)
+
+	for _, server := range slices.Sorted(maps.Keys(counts)) {
+		writeValue(&buf, "tftui_log_packets_total", server, float64(counts[server]))
+	}
+
+	return buf.Bytes()
+}
+
+func writeHeader(buf *bytes.Buffer, name string, help string, kind metricType) {
+	_, _ = fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeValue(buf *bytes.Buffer, name string, server string, value float64) {
+	_, _ = fmt.Fprintf(buf, "%s{server=%s} %s\n", name, quoteLabel(server),
+		strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines as required by the format.
+func quoteLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// float32Value converts the value using the shortest representation of the float32, so that a value
+// such as 66.67 is not reported as 66.66999816894531.
+func float32Value(value float32) float64 {
+	converted, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'g', -1, 32), 64)
+
+	return converted
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
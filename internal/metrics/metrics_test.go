@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/metrics"
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/tf/rcon"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	snapshots []state.Snapshot
+	packets   map[string]int64
+}
+
+func (s testSource) Snapshots() []state.Snapshot {
+	return s.snapshots
+}
+
+func (s testSource) LogPacketCounts() map[string]int64 {
+	return s.packets
+}
+
+func TestMetrics(t *testing.T) {
+	snapshot := state.Snapshot{
+		HostPort:   "127.0.0.1:27015",
+		EventCount: 42,
+		RCON:       rcon.Health{Connected: true, Latency: time.Millisecond * 250, Commands: 10, Errors: 2},
+	}
+	snapshot.Status.Stats.CPU = 12.5
+	snapshot.Status.Stats.FPS = 66.67
+
+	source := testSource{
+		snapshots: []state.Snapshot{snapshot},
+		packets:   map[string]int64{"127.0.0.1:27015": 100, "": 3},
+	}
+
+	server := httptest.NewServer(metrics.New("", source).Handler())
+	t.Cleanup(server.Close)
+
+	resp, errGet := http.Get(server.URL + "/metrics") //nolint:noctx
+	require.NoError(t, errGet)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+
+	body, errBody := io.ReadAll(resp.Body)
+	require.NoError(t, errBody)
+
+	for _, expected := range []string{
+		"# TYPE tftui_server_cpu_percent gauge\n",
+		`tftui_server_cpu_percent{server="127.0.0.1:27015"} 12.5`,
+		`tftui_server_fps{server="127.0.0.1:27015"} 66.67`,
+		"# TYPE tftui_server_log_events_total counter\n",
+		`tftui_server_log_events_total{server="127.0.0.1:27015"} 42`,
+		`tftui_rcon_connected{server="127.0.0.1:27015"} 1`,
+		`tftui_rcon_latency_seconds{server="127.0.0.1:27015"} 0.25`,
+		`tftui_rcon_commands_total{server="127.0.0.1:27015"} 10`,
+		`tftui_rcon_errors_total{server="127.0.0.1:27015"} 2`,
+		`tftui_log_packets_total{server=""} 3`,
+		`tftui_log_packets_total{server="127.0.0.1:27015"} 100`,
+	} {
+		require.Contains(t, string(body), expected)
+	}
+}
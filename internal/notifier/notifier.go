@@ -0,0 +1,234 @@
+// Package notifier sends notifications about players to external services using webhooks.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+)
+
+const (
+	sendTimeout = time.Second * 10
+	username    = "tf-tui"
+	// embedColour is the colour of the bar shown beside discord embeds.
+	embedColour = 0xb33a3a
+)
+
+var (
+	ErrFormat = errors.New("unknown webhook format")
+	ErrSend   = errors.New("failed to send notification")
+)
+
+// Format is the format of the payload sent to the webhook.
+type Format string
+
+const (
+	// Generic sends the notification as a flat json object.
+	Generic Format = "generic"
+	// Discord sends the notification as a discord embed.
+	Discord Format = "discord"
+)
+
+// ParseFormat returns the format matching the value, defaulting to Generic when empty.
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", Generic:
+		return Generic, nil
+	case Discord:
+		return Discord, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrFormat, value)
+	}
+}
+
+// Link is a link to an external site with more information about the player.
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// NewLinks generates the links for the player using the user configured links.
+func NewLinks(userLinks []config.UserLink, steamID steamid.SteamID) []Link {
+	links := make([]Link, len(userLinks))
+	for idx, link := range userLinks {
+		links[idx] = Link{Name: link.Name, URL: link.Generate(steamID)}
+	}
+
+	return links
+}
+
+// Notification describes something that happened to a player on a server.
+type Notification struct {
+	Title    string
+	Player   string
+	SteamID  steamid.SteamID
+	Server   string
+	Hostname string
+	Reason   string
+	Links    []Link
+	// CreatedOn is when the event being notified about happened.
+	CreatedOn time.Time
+}
+
+// Webhook posts notifications to a single webhook url.
+type Webhook struct {
+	url    string
+	format Format
+	client *http.Client
+}
+
+func NewWebhook(client *http.Client, url string, format Format) *Webhook {
+	return &Webhook{client: client, url: url, format: format}
+}
+
+// Send posts the notification to the webhook.
+func (w *Webhook) Send(ctx context.Context, notification Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var payload any
+	switch w.format {
+	case Discord:
+		payload = newDiscordPayload(notification)
+	case Generic:
+		payload = newGenericPayload(notification)
+	default:
+		return fmt.Errorf("%w: %s", ErrFormat, w.format)
+	}
+
+	body, errBody := json.Marshal(payload)
+	if errBody != nil {
+		return errors.Join(errBody, ErrSend)
+	}
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if errReq != nil {
+		return errors.Join(errReq, ErrSend)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, errResp := w.client.Do(req)
+	if errResp != nil {
+		return errors.Join(errResp, ErrSend)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%w: unexpected status %d", ErrSend, resp.StatusCode)
+	}
+
+	return nil
+}
+
+type genericPayload struct {
+	Title     string    `json:"title"`
+	Player    string    `json:"player"`
+	SteamID   string    `json:"steam_id"`
+	Server    string    `json:"server"`
+	Hostname  string    `json:"hostname"`
+	Reason    string    `json:"reason"`
+	Links     []Link    `json:"links"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+func newGenericPayload(notification Notification) genericPayload {
+	payload := genericPayload{
+		Title:     notification.Title,
+		Player:    notification.Player,
+		Server:    notification.Server,
+		Hostname:  notification.Hostname,
+		Reason:    notification.Reason,
+		Links:     notification.Links,
+		CreatedOn: notification.CreatedOn,
+	}
+
+	if payload.Links == nil {
+		payload.Links = []Link{}
+	}
+
+	if notification.SteamID.Valid() {
+		payload.SteamID = notification.SteamID.String()
+	}
+
+	return payload
+}
+
+// https://discord.com/developers/docs/resources/webhook#execute-webhook
+type discordPayload struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func newDiscordPayload(notification Notification) discordPayload {
+	embed := discordEmbed{
+		Title:       notification.Title,
+		Description: notification.Reason,
+		Color:       embedColour,
+		Fields: []discordField{
+			{Name: "Player", Value: valueOrUnknown(notification.Player), Inline: true},
+		},
+	}
+
+	if notification.SteamID.Valid() {
+		embed.Fields = append(embed.Fields, discordField{
+			Name: "SteamID", Value: notification.SteamID.String(), Inline: true,
+		})
+	}
+
+	server := notification.Server
+	if notification.Hostname != "" {
+		server = fmt.Sprintf("%s (%s)", notification.Hostname, notification.Server)
+	}
+
+	embed.Fields = append(embed.Fields, discordField{Name: "Server", Value: valueOrUnknown(server)})
+
+	if len(notification.Links) > 0 {
+		links := make([]string, len(notification.Links))
+		for idx, link := range notification.Links {
+			links[idx] = fmt.Sprintf("[%s](%s)", link.Name, link.URL)
+		}
+
+		embed.Fields = append(embed.Fields, discordField{Name: "Links", Value: strings.Join(links, " | ")})
+	}
+
+	if !notification.CreatedOn.IsZero() {
+		embed.Timestamp = notification.CreatedOn.UTC().Format(time.RFC3339)
+	}
+
+	return discordPayload{Username: username, Embeds: []discordEmbed{embed}}
+}
+
+// valueOrUnknown returns a placeholder for empty values, as discord rejects fields with empty values.
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
@@ -0,0 +1,106 @@
+package notifier_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotification() notifier.Notification {
+	steamID := steamid.New(76561197960265730)
+
+	return notifier.Notification{
+		Title:    "Bot detector match joined",
+		Player:   "Cheater",
+		SteamID:  steamID,
+		Server:   "127.0.0.1:27015",
+		Hostname: "Test Server",
+		Reason:   "Found in: official (cheater)",
+		Links: notifier.NewLinks([]config.UserLink{
+			{Name: "demos.tf", URL: "https://demos.tf/profiles/%s", Format: config.Steam64},
+		}, steamID),
+		CreatedOn: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// send posts the notification to a test server, returning the decoded request body.
+func send(t *testing.T, format notifier.Format, status int) (map[string]any, error) {
+	t.Helper()
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	err := notifier.NewWebhook(server.Client(), server.URL, format).Send(t.Context(), newTestNotification())
+
+	return payload, err
+}
+
+func TestGeneric(t *testing.T) {
+	payload, errSend := send(t, notifier.Generic, http.StatusOK)
+	require.NoError(t, errSend)
+	require.Equal(t, "Cheater", payload["player"])
+	require.Equal(t, "76561197960265730", payload["steam_id"])
+	require.Equal(t, "Test Server", payload["hostname"])
+	require.Equal(t, "Found in: official (cheater)", payload["reason"])
+	require.Equal(t, []any{map[string]any{
+		"name": "demos.tf", "url": "https://demos.tf/profiles/76561197960265730",
+	}}, payload["links"])
+}
+
+func TestDiscord(t *testing.T) {
+	payload, errSend := send(t, notifier.Discord, http.StatusNoContent)
+	require.NoError(t, errSend)
+
+	embeds, ok := payload["embeds"].([]any)
+	require.True(t, ok)
+	require.Len(t, embeds, 1)
+
+	embed, ok := embeds[0].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "Bot detector match joined", embed["title"])
+	require.Equal(t, "Found in: official (cheater)", embed["description"])
+	require.Equal(t, "2025-01-02T03:04:05Z", embed["timestamp"])
+
+	fields := map[string]string{}
+	for _, value := range embed["fields"].([]any) { //nolint:forcetypeassert
+		field := value.(map[string]any) //nolint:forcetypeassert
+		fields[field["name"].(string)] = field["value"].(string)
+	}
+
+	require.Equal(t, map[string]string{
+		"Player":  "Cheater",
+		"SteamID": "76561197960265730",
+		"Server":  "Test Server (127.0.0.1:27015)",
+		"Links":   "[demos.tf](https://demos.tf/profiles/76561197960265730)",
+	}, fields)
+}
+
+func TestSendError(t *testing.T) {
+	_, errSend := send(t, notifier.Generic, http.StatusBadRequest)
+	require.ErrorIs(t, errSend, notifier.ErrSend)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, errFormat := notifier.ParseFormat("")
+	require.NoError(t, errFormat)
+	require.Equal(t, notifier.Generic, format)
+
+	format, errFormat = notifier.ParseFormat("discord")
+	require.NoError(t, errFormat)
+	require.Equal(t, notifier.Discord, format)
+
+	_, errFormat = notifier.ParseFormat("slack")
+	require.ErrorIs(t, errFormat, notifier.ErrFormat)
+}
@@ -16,30 +16,64 @@ import (
 var errBlackBox = errors.New("failed to save blackbox event")
 
 // blackBox handles recording various game events for long term storage.
+//
+// Events are accumulated into the current Match which is finalized and written to the database once
+// the match ends, either by disconnecting, changing maps or shutting down.
 type blackBox struct {
-	db        *store.Queries
-	logEvents chan events.Event
-	validIDs  []steamid.SteamID
-	match     Match
+	db            *store.Queries
+	logEvents     chan events.Event
+	playerUpdates chan Players
+	// players is the most recent player state, used to resolve player names to steam ids.
+	players Players
+	// names holds the last name seen for each steam id in status output and srcds log lines. There is no player
+	// state to resolve names with when replaying, so this is often the only way to identify the players.
+	names    map[steamid.SteamID]string
+	selfSID  steamid.SteamID
+	validIDs []steamid.SteamID
+	match    Match
+	// lastEvent is the timestamp of the most recent log event. Logs can be delayed, or hours old when
+	// replaying, so this is used instead of the current time to keep the match times consistent.
+	lastEvent time.Time
 }
 
-func newBlackBox(conn *store.Queries, incomingEvents chan events.Event) *blackBox {
-	return &blackBox{db: conn, logEvents: incomingEvents}
+func newBlackBox(conn *store.Queries, incomingEvents chan events.Event, selfSID steamid.SteamID) *blackBox {
+	return &blackBox{
+		db:            conn,
+		logEvents:     incomingEvents,
+		playerUpdates: make(chan Players, 1),
+		names:         map[steamid.SteamID]string{},
+		selfSID:       selfSID,
+		match:         newMatch(),
+	}
 }
 
 func (b *blackBox) Start(ctx context.Context) {
 	for {
 		select {
+		case players := <-b.playerUpdates:
+			b.onPlayers(players)
 		case event := <-b.logEvents:
+			if !event.Timestamp.IsZero() {
+				b.lastEvent = event.Timestamp
+			}
+
+			// Every srcds log line referencing a player includes their steam id.
+			for _, player := range events.ParseLogPlayers(event.Raw) {
+				b.remember(player.PlayerSID, player.Player)
+			}
+
 			var err error
 			switch data := event.Data.(type) {
 			case events.MsgEvent:
+				b.match.touch(event.Timestamp)
 				err = b.onMsg(ctx, event.Timestamp, data)
 			case events.KillEvent:
-				b.onKill(ctx, data)
+				b.match.touch(event.Timestamp)
+				err = b.onKill(ctx, event.Timestamp, data)
 			case events.ConnectEvent:
-				b.onConnect(ctx, event)
+				b.match.touch(event.Timestamp)
 			case events.DisconnectEvent:
+				err = b.finalize(ctx)
 			case events.AddressEvent:
 				b.match.Address = data.Address.String()
 			case events.HostnameEvent:
@@ -48,7 +82,11 @@ func (b *blackBox) Start(ctx context.Context) {
 				b.match.Tags = data.Tags
 			case events.LobbyEvent:
 			case events.StatusIDEvent:
+				b.match.touch(event.Timestamp)
+				b.onStatusID(data)
 			case events.MapEvent:
+				err = b.onMap(ctx, data)
+				b.match.touch(event.Timestamp)
 			case events.AnyEvent:
 			}
 
@@ -56,20 +94,195 @@ func (b *blackBox) Start(ctx context.Context) {
 				slog.Error("Failed to handle log event", slog.String("error", err.Error()))
 			}
 		case <-ctx.Done():
+			// The parent context is already cancelled, but we still want the final match written.
+			if err := b.finalize(context.WithoutCancel(ctx)); err != nil {
+				slog.Error("Failed to save final match", slog.String("error", err.Error()))
+			}
+
 			return
 		}
 	}
 }
 
-func (b *blackBox) onConnect(_ context.Context, _ events.Event) {
-	// if len(b.match.Players) > 0 {
-	// 	// Save it
-	// }
-	b.match = Match{
-		Players:  []*PlayerHistory{},
-		Messages: []ChatMessage{},
-		Tags:     []string{},
+// syncPlayers queues the latest player state to be merged into the current match. If an update
+// is already pending, it is dropped as the next update will supersede it anyway.
+func (b *blackBox) syncPlayers(players Players) {
+	select {
+	case b.playerUpdates <- players:
+	default:
+	}
+}
+
+func (b *blackBox) onPlayers(players Players) {
+	if len(players) == 0 {
+		return
+	}
+
+	b.match.touch(b.now())
+	b.players = players
+
+	for _, update := range players {
+		if !update.SteamID.Valid() {
+			continue
+		}
+
+		player := b.player(update.SteamID)
+		player.Name = update.Name
+		player.Score = update.Score
+		player.Deaths = update.Deaths
+		player.Ping = update.Ping
+		player.Team = update.Team
+		if update.Time > 0 {
+			player.Connected = update.Time
+		}
+	}
+}
+
+func (b *blackBox) onStatusID(event events.StatusIDEvent) {
+	if !event.PlayerSID.Valid() {
+		return
+	}
+
+	b.remember(event.PlayerSID, event.Player)
+
+	player := b.player(event.PlayerSID)
+	player.Name = event.Player
+	player.Ping = event.Ping
+	player.Connected = event.Connected
+}
+
+func (b *blackBox) onMap(ctx context.Context, event events.MapEvent) error {
+	// Status output repeats the current map name, so only a different map is considered a new match.
+	if b.match.MapName == event.MapName {
+		return nil
+	}
+
+	var err error
+	if b.match.MapName != "" {
+		err = b.finalize(ctx)
 	}
+
+	b.match.MapName = event.MapName
+
+	return err
+}
+
+// finalize writes the current match and all of its participants to the database and starts a new match.
+func (b *blackBox) finalize(ctx context.Context) error {
+	defer func() {
+		b.match = newMatch()
+	}()
+
+	if len(b.match.Players) == 0 && b.match.MatchID == 0 {
+		return nil
+	}
+
+	if errMatch := b.ensureMatch(ctx); errMatch != nil {
+		return errMatch
+	}
+
+	if err := b.db.UpdateMatch(ctx, store.UpdateMatchParams{
+		Hostname: b.match.Hostname,
+		Address:  b.match.Address,
+		MapName:  b.match.MapName,
+		Duration: int64(b.match.Duration().Seconds()),
+		MatchID:  b.match.MatchID,
+	}); err != nil {
+		return errors.Join(err, errBlackBox)
+	}
+
+	for _, player := range b.match.Players {
+		if !player.SteamID.Valid() {
+			continue
+		}
+
+		if errEnsure := b.ensureSID(ctx, player.SteamID, player.Name); errEnsure != nil {
+			return errEnsure
+		}
+
+		if err := b.db.InsertMatchPlayer(ctx, store.InsertMatchPlayerParams{
+			MatchID:   b.match.MatchID,
+			SteamID:   player.SteamID.Int64(),
+			Score:     int64(player.Score),
+			Deaths:    int64(player.Deaths),
+			Ping:      int64(player.Ping),
+			Connected: int64(player.Connected),
+		}); err != nil {
+			return errors.Join(err, errBlackBox)
+		}
+	}
+
+	slog.Debug("Saved match", slog.Int64("match_id", b.match.MatchID),
+		slog.String("hostname", b.match.Hostname), slog.Int("players", len(b.match.Players)))
+
+	return nil
+}
+
+// ensureMatch creates the database entry for the current match if it does not exist yet. The match
+// is created early so that chat messages are able to reference it before the match is finalized.
+func (b *blackBox) ensureMatch(ctx context.Context) error {
+	if b.match.MatchID > 0 {
+		return nil
+	}
+
+	if b.match.CreatedOn.IsZero() {
+		b.match.touch(b.now())
+	}
+
+	matchID, errMatch := b.db.InsertMatch(ctx, store.InsertMatchParams{
+		Hostname:  b.match.Hostname,
+		Address:   b.match.Address,
+		MapName:   b.match.MapName,
+		CreatedOn: b.match.CreatedOn.Unix(),
+	})
+	if errMatch != nil {
+		return errors.Join(errMatch, errBlackBox)
+	}
+
+	b.match.MatchID = matchID
+
+	return nil
+}
+
+// remember records the name the player is currently using so that it can be resolved back to their steam id.
+func (b *blackBox) remember(steamID steamid.SteamID, name string) {
+	if !steamID.Valid() || name == "" {
+		return
+	}
+
+	b.names[steamID] = name
+}
+
+// resolveName returns the steam id of the player using the name, checking both the player state and the
+// names seen in the logs. As names are not unique, no match is returned when multiple players share the name.
+func (b *blackBox) resolveName(name string) (steamid.SteamID, bool) {
+	var found []steamid.SteamID
+	for _, player := range b.players.AllByName(name) {
+		if !slices.Contains(found, player.SteamID) {
+			found = append(found, player.SteamID)
+		}
+	}
+
+	for steamID, seenName := range b.names {
+		if seenName == name && !slices.Contains(found, steamID) {
+			found = append(found, steamID)
+		}
+	}
+
+	if len(found) != 1 {
+		return steamid.SteamID{}, false
+	}
+
+	return found[0], true
+}
+
+// now returns the timestamp of the most recent log event, or the current time if there has not been one yet.
+func (b *blackBox) now() time.Time {
+	if b.lastEvent.IsZero() {
+		return time.Now()
+	}
+
+	return b.lastEvent
 }
 
 func (b *blackBox) player(steamID steamid.SteamID) *PlayerHistory {
@@ -85,26 +298,69 @@ func (b *blackBox) player(steamID steamid.SteamID) *PlayerHistory {
 	return player
 }
 
-func (b *blackBox) onKill(_ context.Context, event events.KillEvent) {
+func (b *blackBox) onKill(ctx context.Context, timeStamp time.Time, event events.KillEvent) error {
+	if !event.PlayerSID.Valid() {
+		event.PlayerSID, _ = b.resolveName(event.Player)
+	}
+
+	if !event.VictimSID.Valid() {
+		event.VictimSID, _ = b.resolveName(event.Victim)
+	}
+
+	// Without both ids there is nothing meaningful that can be recorded.
+	if !event.PlayerSID.Valid() || !event.VictimSID.Valid() {
+		return nil
+	}
+
 	player := b.player(event.PlayerSID)
 	player.Kills = append(player.Kills, PlayerKill{
 		Source:    event.PlayerSID,
 		Victim:    event.VictimSID,
 		Weapon:    event.Weapon,
 		Crit:      event.Crit,
-		CreatedOn: event.Timestamp,
+		CreatedOn: timeStamp,
 	})
+
+	if !b.selfSID.Valid() || event.PlayerSID.Equal(event.VictimSID) {
+		return nil
+	}
+
+	switch {
+	case event.PlayerSID.Equal(b.selfSID):
+		return b.tallyKills(ctx, event.VictimSID, event.Victim, 1, 0)
+	case event.VictimSID.Equal(b.selfSID):
+		return b.tallyKills(ctx, event.PlayerSID, event.Player, 0, 1)
+	default:
+		return nil
+	}
+}
+
+// tallyKills adds to the lifetime kill counts between us and another player.
+func (b *blackBox) tallyKills(ctx context.Context, steamID steamid.SteamID, name string, killsAgainst int64, killedBy int64) error {
+	if errEnsure := b.ensureSID(ctx, steamID, name); errEnsure != nil {
+		return errEnsure
+	}
+
+	if err := b.db.UpdatePlayerKills(ctx, store.UpdatePlayerKillsParams{
+		KillsAgainst: killsAgainst,
+		KilledBy:     killedBy,
+		SteamID:      steamID.Int64(),
+	}); err != nil {
+		return errors.Join(err, errBlackBox)
+	}
+
+	return nil
 }
 
 // ensureSID handles making sure the players steam_id FK is satisfied.
-func (b *blackBox) ensureSID(ctx context.Context, steamID steamid.SteamID) error {
+func (b *blackBox) ensureSID(ctx context.Context, steamID steamid.SteamID, name string) error {
 	if slices.Contains(b.validIDs, steamID) {
 		return nil
 	}
 
 	args := store.InsertPlayerParams{
 		SteamID:   steamID.Int64(),
-		Name:      "",
+		Name:      name,
 		CreatedOn: time.Now().Unix(),
 		UpdatedOn: time.Now().Unix(),
 	}
@@ -117,17 +373,36 @@ func (b *blackBox) ensureSID(ctx context.Context, steamID steamid.SteamID) error
 	return nil
 }
 
+// onMsg records a chat message. The author is resolved by name against the known players. When the name
+// is unknown, or shared by multiple players, the author cannot be determined with any certainty so the
+// message is not recorded rather than being attributed to the wrong player.
 func (b *blackBox) onMsg(ctx context.Context, timeStamp time.Time, event events.MsgEvent) error {
-	if errEnsure := b.ensureSID(ctx, event.PlayerSID); errEnsure != nil {
+	if !event.PlayerSID.Valid() {
+		steamID, found := b.resolveName(event.Player)
+		if !found {
+			slog.Debug("Cannot resolve chat author", slog.String("name", event.Player))
+
+			return nil
+		}
+
+		event.PlayerSID = steamID
+	}
+
+	if errEnsure := b.ensureSID(ctx, event.PlayerSID, event.Player); errEnsure != nil {
 		return errEnsure
 	}
 
+	if errMatch := b.ensureMatch(ctx); errMatch != nil {
+		return errMatch
+	}
+
 	teamOnly := int64(0)
 	if event.TeamOnly {
 		teamOnly = 1
 	}
 
 	if err := b.db.InsertChat(ctx, store.InsertChatParams{
+		MatchID:   b.match.MatchID,
 		SteamID:   event.PlayerSID.Int64(),
 		Name:      event.Player,
 		Message:   event.Message,
@@ -159,14 +434,40 @@ type PlayerHistory struct {
 	Kills     []PlayerKill
 }
 
-type ChatMessage struct {
-	SteamID steamid.SteamID
+type Match struct {
+	// MatchID is the database id of the match. It is 0 until the match has been written.
+	MatchID   int64
+	Players   []*PlayerHistory
+	Hostname  string
+	Address   string
+	MapName   string
+	Tags      []string
+	CreatedOn time.Time
+	UpdatedOn time.Time
+}
+
+func newMatch() Match {
+	return Match{
+		Players: []*PlayerHistory{},
+		Tags:    []string{},
+	}
 }
 
-type Match struct {
-	Players  []*PlayerHistory
-	Messages []ChatMessage
-	Hostname string
-	Address  string
-	Tags     []string
+// touch extends the time window of the match to include the timestamp.
+func (m *Match) touch(timestamp time.Time) {
+	if m.CreatedOn.IsZero() {
+		m.CreatedOn = timestamp
+	}
+
+	if timestamp.After(m.UpdatedOn) {
+		m.UpdatedOn = timestamp
+	}
+}
+
+func (m *Match) Duration() time.Duration {
+	if m.CreatedOn.IsZero() {
+		return 0
+	}
+
+	return m.UpdatedOn.Sub(m.CreatedOn)
 }
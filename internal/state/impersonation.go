@@ -0,0 +1,102 @@
+package state
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// invisibleRunes are characters that render as nothing, or as plain whitespace, but are not covered
+// by the unicode format category.
+var invisibleRunes = []rune{ //nolint:gochecknoglobals
+	'\u115f', // Hangul choseong filler
+	'\u1160', // Hangul jungseong filler
+	'\u2800', // Braille pattern blank
+	'\u3164', // Hangul filler
+	'\uffa0', // Halfwidth hangul filler
+}
+
+// homoglyphs maps commonly abused characters to the latin character they are visually identical to.
+// Fullwidth and other compatibility forms are already handled by NFKC normalization.
+var homoglyphs = map[rune]rune{ //nolint:gochecknoglobals
+	// Cyrillic
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ј': 'j',
+	'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l', 'һ': 'h',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T',
+	'Х': 'X', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J', 'Ү': 'Y', 'Ԛ': 'Q', 'Ԝ': 'W',
+	// Greek
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ρ': 'p', 'ι': 'i', 'υ': 'u',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O',
+	'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	// Latin lookalikes
+	'ı': 'i', 'ɑ': 'a', 'ɡ': 'g', 'ʏ': 'y', 'ᴄ': 'c', 'ᴏ': 'o', 'ᴠ': 'v', 'ᴡ': 'w', 'ᴢ': 'z',
+}
+
+// canonicalName reduces a name to the form that it is visually rendered as. Invisible characters are
+// removed, compatibility characters are normalized and known homoglyphs are replaced by their latin
+// equivalent. Two names with the same canonical name are indistinguishable to other players.
+func canonicalName(name string) string {
+	var builder strings.Builder
+
+	for _, char := range norm.NFKC.String(name) {
+		switch {
+		case unicode.Is(unicode.Cf, char), unicode.Is(unicode.Mn, char), slices.Contains(invisibleRunes, char):
+			continue
+		case unicode.IsSpace(char):
+			builder.WriteRune(' ')
+		default:
+			if replacement, ok := homoglyphs[char]; ok {
+				char = replacement
+			}
+
+			builder.WriteRune(char)
+		}
+	}
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// findImpersonators returns the players that share a visually identical name with another player, mapped
+// to the steam id of the player they are likely impersonating. When only one of the names has been altered,
+// the altered one is the impersonator. Otherwise, the player who connected most recently is assumed to be
+// the one copying the name.
+func findImpersonators(players Players) map[steamid.SteamID]steamid.SteamID {
+	found := map[steamid.SteamID]steamid.SteamID{}
+	groups := map[string]Players{}
+
+	for _, player := range players {
+		if player.Name == "" {
+			continue
+		}
+
+		canonical := canonicalName(player.Name)
+		groups[canonical] = append(groups[canonical], player)
+	}
+
+	for canonical, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		original := group[0]
+		for _, player := range group[1:] {
+			originalAltered := original.Name != canonical
+			playerAltered := player.Name != canonical
+
+			if originalAltered && !playerAltered || originalAltered == playerAltered && player.Time > original.Time {
+				original = player
+			}
+		}
+
+		for _, player := range group {
+			if !player.SteamID.Equal(original.SteamID) {
+				found[player.SteamID] = original.SteamID
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalName(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "Player One", "Player One"},
+		{"zero width", "Play\u200ber\u200d One\u2060", "Player One"},
+		{"hangul filler", "\u3164Player\u115f One\uffa0", "Player One"},
+		{"cyrillic", "\u0420l\u0430y\u0435r \u041en\u0435", "Player One"},
+		{"greek", "\u03a1l\u03b1yer \u039fne", "Player One"},
+		{"fullwidth", "\uff30\uff4c\uff41\uff59\uff45\uff52\u3000\uff2f\uff4e\uff45", "Player One"},
+		{"whitespace", "  Player \u00a0\t One ", "Player One"},
+		{"combining joiner", "Pla\u034fyer One", "Player One"},
+		{"accented", "Pl\u00e1yer One", "Pl\u00e1yer One"},
+	} {
+		require.Equal(t, testCase.expected, canonicalName(testCase.input), testCase.name)
+	}
+}
+
+func TestFindImpersonators(t *testing.T) {
+	var (
+		original = steamid.New("[U:1:1]")
+		copier   = steamid.New("[U:1:2]")
+		other    = steamid.New("[U:1:3]")
+	)
+
+	for _, testCase := range []struct {
+		name     string
+		players  Players
+		expected map[steamid.SteamID]steamid.SteamID
+	}{
+		{
+			name:     "unique names",
+			players:  Players{{SteamID: original, Name: "Player One"}, {SteamID: copier, Name: "Player Two"}},
+			expected: map[steamid.SteamID]steamid.SteamID{},
+		},
+		{
+			name: "altered name is the impersonator",
+			players: Players{
+				{SteamID: copier, Name: "Player\u200b One", Time: 600},
+				{SteamID: original, Name: "Player One", Time: 60},
+			},
+			expected: map[steamid.SteamID]steamid.SteamID{copier: original},
+		},
+		{
+			name: "homoglyph name is the impersonator",
+			players: Players{
+				{SteamID: original, Name: "Player One", Time: 60},
+				{SteamID: copier, Name: "\u0420layer One", Time: 600},
+			},
+			expected: map[steamid.SteamID]steamid.SteamID{copier: original},
+		},
+		{
+			name: "identical names use the longest connected",
+			players: Players{
+				{SteamID: copier, Name: "Player One", Time: 30},
+				{SteamID: original, Name: "Player One", Time: 1200},
+			},
+			expected: map[steamid.SteamID]steamid.SteamID{copier: original},
+		},
+		{
+			name: "both altered use the longest connected",
+			players: Players{
+				{SteamID: original, Name: "Player\u200b One", Time: 1200},
+				{SteamID: copier, Name: "Player\u3164 One", Time: 30},
+				{SteamID: other, Name: "Someone Else", Time: 30},
+			},
+			expected: map[steamid.SteamID]steamid.SteamID{copier: original},
+		},
+		{
+			name:     "unnamed players are ignored",
+			players:  Players{{SteamID: original}, {SteamID: copier}},
+			expected: map[steamid.SteamID]steamid.SteamID{},
+		},
+	} {
+		require.Equal(t, testCase.expected, findImpersonators(testCase.players), testCase.name)
+	}
+}
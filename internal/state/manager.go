@@ -3,7 +3,9 @@ package state
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,8 +15,10 @@ import (
 	"github.com/leighmacdonald/tf-tui/internal/config"
 	"github.com/leighmacdonald/tf-tui/internal/meta"
 	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
 	"github.com/leighmacdonald/tf-tui/internal/tf/console"
 	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/leighmacdonald/tf-tui/internal/tf/rcon"
 	"github.com/leighmacdonald/tf-tui/internal/tfapi"
 )
 
@@ -24,7 +28,15 @@ const (
 	removeInterval = time.Second
 )
 
-var errNoServersFound = errors.New("no servers configured")
+var (
+	errNoServersFound  = errors.New("no servers configured")
+	ErrSaveNotes       = errors.New("failed to save player notes")
+	ErrSaveMark        = errors.New("failed to save player mark")
+	ErrVoteKick        = errors.New("failed to call vote kick")
+	ErrVoteKickMode    = errors.New("vote kicks are only available in client mode")
+	ErrUnknownServer   = errors.New("unknown server address")
+	ErrShutdownTimeout = errors.New("timed out waiting for servers to shut down")
+)
 
 type serverMetaUpdate struct {
 	logAddress int
@@ -39,10 +51,17 @@ func NewManager(router *events.Router, conf config.Config, metaFetcher *meta.Fet
 	var servers []*serverState
 	var source console.Source
 
+	// All rcon commands share a single persistent connection per server.
+	pool := rcon.NewPool()
+
 	if conf.ServerModeEnabled {
-		remoteOpts := console.RemoteOpts{ListenAddress: conf.ServerBindAddress, ServerHostMap: map[int]string{}}
+		remoteOpts := console.RemoteOpts{
+			ListenAddress: conf.ServerBindAddress,
+			ServerHostMap: map[int]string{},
+			CapturePath:   conf.ServerCapturePath,
+		}
 		for _, server := range conf.Servers {
-			servers = append(servers, newServerState(conf, server, router, bdFetcher, dbConn))
+			servers = append(servers, newServerState(conf, server, router, bdFetcher, dbConn, pool))
 			remoteOpts.ServerHostMap[server.LogSecret] = server.Address
 		}
 
@@ -53,8 +72,8 @@ func NewManager(router *events.Router, conf config.Config, metaFetcher *meta.Fet
 		source = logSource
 
 	} else {
-		source = console.NewLocal(conf.ConsoleLogPath)
-		servers = []*serverState{newServerState(conf, conf.Client, router, bdFetcher, dbConn)}
+		source = console.NewLocal(conf.Client.Address, conf.ConsoleLogPath)
+		servers = []*serverState{newServerState(conf, conf.Client, router, bdFetcher, dbConn, pool)}
 	}
 
 	if len(servers) == 0 {
@@ -66,6 +85,9 @@ func NewManager(router *events.Router, conf config.Config, metaFetcher *meta.Fet
 		metaFetcher:  metaFetcher,
 		config:       conf,
 		logSource:    source,
+		rcon:         pool,
+		db:           store.New(dbConn),
+		running:      &sync.WaitGroup{},
 	}, nil
 }
 
@@ -84,6 +106,20 @@ type Manager struct {
 	metaQueue      chan serverMetaUpdate
 	metaInFlight   atomic.Bool
 	config         config.Config
+	rcon           *rcon.Pool
+	db             *store.Queries
+	// running tracks the goroutines started by Start so that Close can wait for them to finish.
+	running *sync.WaitGroup
+}
+
+// SetReplay replaces the log source used for the configured mode with a replay of saved logs. The servers are
+// not contacted over rcon while replaying, so players are only known from the replayed lines. This must be called
+// before Start.
+func (s *Manager) SetReplay(source console.Source) {
+	s.logSource = source
+	for _, server := range s.serverStates {
+		server.replay = true
+	}
 }
 
 func (s *Manager) Snapshots() []Snapshot {
@@ -95,29 +131,205 @@ func (s *Manager) Snapshots() []Snapshot {
 	return snapshots
 }
 
-func (s *Manager) Close(ctx context.Context) {
+// LogPacketCounts returns the number of log packets received from each server, keyed by the server address.
+// Packets sent without a log secret are counted under an empty address. This is only populated in server mode.
+func (s *Manager) LogPacketCounts() map[string]int64 {
+	counts := map[string]int64{}
+
+	remote, ok := s.logSource.(*console.Remote)
+	if !ok {
+		return counts
+	}
+
+	for logSecret, count := range remote.PacketCounts() {
+		counts[remote.ServerHostMap[logSecret]] += count
+	}
+
+	return counts
+}
+
+// SaveNotes creates or updates the notes for a player.
+func (s *Manager) SaveNotes(ctx context.Context, steamID steamid.SteamID, notes string) error {
+	existing, errExisting := s.db.GetNotes(ctx, []int64{steamID.Int64()})
+	if errExisting != nil {
+		return errors.Join(errExisting, ErrSaveNotes)
+	}
+
+	now := time.Now().Unix()
+
+	if len(existing) > 0 {
+		if err := s.db.UpdateNote(ctx, store.UpdateNoteParams{Note: notes, UpdatedOn: now, SteamID: steamID.Int64()}); err != nil {
+			return errors.Join(err, ErrSaveNotes)
+		}
+	} else {
+		if err := s.ensurePlayer(ctx, steamID); err != nil {
+			return errors.Join(err, ErrSaveNotes)
+		}
+
+		if err := s.db.InsertNote(ctx, store.InsertNoteParams{SteamID: steamID.Int64(), Note: notes, UpdatedOn: now}); err != nil {
+			return errors.Join(err, ErrSaveNotes)
+		}
+	}
+
+	for _, server := range s.serverStates {
+		server.setNotes(steamID, notes)
+	}
+
+	return nil
+}
+
+// MarkPlayer tags a player with the provided tags and reason. Passing no tags removes the mark.
+func (s *Manager) MarkPlayer(ctx context.Context, steamID steamid.SteamID, tags []string, reason string) error {
+	now := time.Now().Unix()
+
+	if len(tags) == 0 {
+		if err := s.db.DeleteMark(ctx, steamID.Int64()); err != nil {
+			return errors.Join(err, ErrSaveMark)
+		}
+	} else {
+		if err := s.ensurePlayer(ctx, steamID); err != nil {
+			return errors.Join(err, ErrSaveMark)
+		}
+
+		if err := s.db.InsertMark(ctx, store.InsertMarkParams{
+			SteamID:   steamID.Int64(),
+			Tags:      strings.Join(tags, ","),
+			Note:      reason,
+			CreatedOn: now,
+			UpdatedOn: now,
+		}); err != nil {
+			return errors.Join(err, ErrSaveMark)
+		}
+	}
+
+	mark := newMark(strings.Join(tags, ","), reason, now)
+	for _, server := range s.serverStates {
+		server.setMark(steamID, mark)
+	}
+
+	return nil
+}
+
+// Exec runs the rcon command on the server with the matching address.
+func (s *Manager) Exec(ctx context.Context, address string, command string) (string, error) {
+	for _, server := range s.serverStates {
+		if server.server.Address == address {
+			return server.rcon.Exec(ctx, command, true)
+		}
+	}
+
+	return "", ErrUnknownServer
+}
+
+// VoteKick calls a vote to kick the player from the server we are currently playing on. A record of the
+// attempt is stored against the player once the vote has been called.
+func (s *Manager) VoteKick(ctx context.Context, steamID steamid.SteamID, userID int, reason tf.KickReason) error {
+	if s.config.ServerModeEnabled || len(s.serverStates) == 0 {
+		return ErrVoteKickMode
+	}
+
+	server := s.serverStates[0]
+	command := fmt.Sprintf(`callvote kick "%d %s"`, userID, reason)
+	if _, err := server.rcon.Exec(ctx, command, false); err != nil {
+		return errors.Join(err, ErrVoteKick)
+	}
+
+	if err := s.ensurePlayer(ctx, steamID); err != nil {
+		return errors.Join(err, ErrVoteKick)
+	}
+
+	if err := s.db.InsertVoteKick(ctx, store.InsertVoteKickParams{
+		SteamID:   steamID.Int64(),
+		UserID:    int64(userID),
+		Reason:    string(reason),
+		Address:   server.server.Address,
+		CreatedOn: time.Now().Unix(),
+	}); err != nil {
+		return errors.Join(err, ErrVoteKick)
+	}
+
+	return nil
+}
+
+// ensurePlayer makes sure the player exists in the database so foreign keys referencing it are satisfied.
+func (s *Manager) ensurePlayer(ctx context.Context, steamID steamid.SteamID) error {
+	// Use the known name if the player is currently in a server. This is only ever called with
+	// players seen in the ui so this should almost always be found.
+	var name string
+	for _, server := range s.serverStates {
+		if player, errPlayer := server.player(steamID); errPlayer == nil {
+			name = player.Name
+
+			break
+		}
+	}
+
+	now := time.Now().Unix()
+
+	return s.db.InsertPlayer(ctx, store.InsertPlayerParams{
+		SteamID:   steamID.Int64(),
+		Name:      name,
+		CreatedOn: now,
+		UpdatedOn: now,
+	})
+}
+
+// Close shuts down the manager, unregistering from the servers log addresses, waiting for the final
+// matches to be recorded and closing all connections. The context passed to Start should be cancelled
+// before calling Close.
+func (s *Manager) Close(ctx context.Context) error {
 	localTimeout, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
-	waitGroup := &sync.WaitGroup{}
-	for _, logSource := range s.serverStates {
-		local := logSource
+	var (
+		err       error
+		errMu     = &sync.Mutex{}
+		waitGroup = &sync.WaitGroup{}
+	)
+
+	for _, server := range s.serverStates {
 		waitGroup.Go(func() {
-			if err := local.close(localTimeout); err != nil {
-				slog.Error("failed to close log source", slog.String("error", err.Error()))
+			if errClose := server.close(localTimeout); errClose != nil {
+				errMu.Lock()
+				err = errors.Join(err, errClose)
+				errMu.Unlock()
 			}
 		})
 	}
 	waitGroup.Wait()
+
+	stopped := make(chan any)
+	go func() {
+		s.running.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-localTimeout.Done():
+		err = errors.Join(err, ErrShutdownTimeout)
+	}
+
+	if errClose := s.logSource.Close(localTimeout); errClose != nil {
+		err = errors.Join(err, errClose)
+	}
+
+	if errClose := s.rcon.Close(); errClose != nil {
+		err = errors.Join(err, errClose)
+	}
+
+	return err
 }
 
+// Start begins updating the state of all servers and blocks while reading from the log source until the
+// context is cancelled.
 func (s *Manager) Start(ctx context.Context, router *events.Router) error {
 	for _, server := range s.serverStates {
-		go func(srv *serverState) {
-			if err := srv.start(ctx); err != nil {
+		s.running.Go(func() {
+			if err := server.start(ctx); err != nil {
 				slog.Error("failed to start server state updater", slog.String("error", err.Error()))
 			}
-		}(server)
+		})
 	}
 
 	if errOpen := s.logSource.Open(); errOpen != nil {
@@ -125,6 +337,9 @@ func (s *Manager) Start(ctx context.Context, router *events.Router) error {
 		return errOpen
 	}
 
+	s.running.Add(1)
+	defer s.running.Done()
+
 	s.logSource.Start(ctx, router)
 
 	return nil
@@ -0,0 +1,317 @@
+package state_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/bd"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/state"
+	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/console"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/leighmacdonald/tf-tui/internal/tf/srcdstest"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// freeUDPAddress finds an unused local udp address for the log listener.
+func freeUDPAddress(t *testing.T) string {
+	t.Helper()
+
+	conn, errListen := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, errListen)
+
+	address := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	return address
+}
+
+func TestManagerServerMode(t *testing.T) {
+	const logSecret = 1234
+
+	server, errServer := srcdstest.NewServer(srcdstest.Options{
+		Password:  "secret",
+		LogSecret: logSecret,
+		Hostname:  "Test Server",
+		CVars:     tf.CVarList{{Name: "sv_cheats", Value: "0", Flags: []string{"nf", "rep"}}},
+		PluginsSM: []tf.GamePlugin{{Name: "Admin Help", Version: "1.13.0.7251", Author: "AlliedModders LLC"}},
+	})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	server.SetPlayers(srcdstest.Player{
+		UserID: 2, Name: "Player One", SteamID: steamid.New(76561197960265730), Ping: 50,
+		Connected: time.Minute, Address: "10.0.0.1:27005",
+	})
+
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	logAddress := freeUDPAddress(t)
+	conf := config.Config{
+		ServerModeEnabled: true,
+		ServerLogAddress:  logAddress,
+		ServerBindAddress: logAddress,
+		Servers:           []config.ServerConfig{{Address: server.Address(), Password: "secret", LogSecret: logSecret}},
+	}
+
+	router := events.NewRouter()
+	manager, errManager := state.NewManager(router, conf, nil, bd.New(nil, nil, nil), database)
+	require.NoError(t, errManager)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		if err := manager.Start(ctx, router); err != nil {
+			t.Errorf("failed to start manager: %v", err)
+		}
+	}()
+
+	require.Eventually(t, func() bool {
+		return slices.Contains(server.LogAddresses(), logAddress)
+	}, time.Second*5, time.Millisecond*50)
+
+	require.Eventually(t, func() bool {
+		snapshots := manager.Snapshots()
+
+		return len(snapshots) == 1 && len(snapshots[0].Players) == 1 && len(snapshots[0].CVars) == 1 &&
+			len(snapshots[0].PluginsSM) == 1
+	}, time.Second*10, time.Millisecond*100)
+
+	snapshot := manager.Snapshots()[0]
+	require.Equal(t, "Test Server", snapshot.Status.ServerName)
+	require.Equal(t, "Player One", snapshot.Players[0].Name)
+	require.True(t, snapshot.RCON.Connected)
+	// Teams and classes are only available from the logs in server mode.
+	require.Equal(t, tf.UNASSIGNED, snapshot.Players[0].Team)
+
+	logEvents := make(chan events.Event, 10)
+	router.ListenFor(server.Address(), logEvents, events.Any)
+
+	const line = `"Player One<2><[U:1:2]><Red>" say "hello"`
+	require.NoError(t, server.Log(line))
+
+	select {
+	case event := <-logEvents:
+		require.True(t, strings.HasSuffix(event.Raw, line), event.Raw)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for log event")
+	}
+
+	require.NoError(t, server.Log(`"Player One<2><[U:1:2]><Red>" joined team "Blue"`))
+	require.NoError(t, server.Log(`"Player One<2><[U:1:2]><Blue>" changed role to "medic"`))
+
+	require.Eventually(t, func() bool {
+		player := manager.Snapshots()[0].Players[0]
+
+		return player.Team == tf.BLU && player.Class == tf.Medic
+	}, time.Second*5, time.Millisecond*50)
+
+	cancel()
+	require.NoError(t, manager.Close(t.Context()))
+	require.Empty(t, server.LogAddresses())
+}
+
+func TestManagerClientLobby(t *testing.T) {
+	server, errServer := srcdstest.NewServer(srcdstest.Options{
+		Password: "secret",
+		Handlers: map[string]srcdstest.Handler{
+			"tf_lobby_debug": func(_ string) string {
+				return "CTFLobbyShared: ID:00021f0d1d2a6d41  1 member(s), 1 pending\n" +
+					"  Member[0] [U:1:2]  team = TF_GC_TEAM_DEFENDERS  type = MATCH_PLAYER\n" +
+					"  Pending[0] [U:1:3]  team = TF_GC_TEAM_INVADERS  type = MATCH_PLAYER\n"
+			},
+		},
+	})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	server.SetPlayers(srcdstest.Player{
+		UserID: 2, Name: "Player One", SteamID: steamid.New("[U:1:2]"), Team: tf.RED, Connected: time.Minute,
+	})
+
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	consoleLogPath := filepath.Join(t.TempDir(), "console.log")
+	require.NoError(t, os.WriteFile(consoleLogPath, nil, 0o600))
+
+	conf := config.Config{
+		ConsoleLogPath: consoleLogPath,
+		Client:         config.ServerConfig{Address: server.Address(), Password: "secret"},
+	}
+
+	router := events.NewRouter()
+	manager, errManager := state.NewManager(router, conf, nil, bd.New(nil, nil, nil), database)
+	require.NoError(t, errManager)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		if err := manager.Start(ctx, router); err != nil {
+			t.Errorf("failed to start manager: %v", err)
+		}
+	}()
+
+	// Lobby members that have not finished connecting are tracked before they appear in the player dump.
+	require.Eventually(t, func() bool {
+		return len(manager.Snapshots()[0].Players) == 2
+	}, time.Second*10, time.Millisecond*100)
+
+	players := manager.Snapshots()[0].Players
+	slices.SortFunc(players, func(a, b state.Player) int { return int(a.SteamID.Int64() - b.SteamID.Int64()) })
+
+	require.Equal(t, "Player One", players[0].Name)
+	require.Equal(t, tf.RED, players[0].Team)
+	require.False(t, players[0].Pending)
+	require.Equal(t, steamid.New("[U:1:3]"), players[1].SteamID)
+	require.Equal(t, tf.BLU, players[1].Team)
+	require.True(t, players[1].Pending)
+	require.Equal(t, "00021f0d1d2a6d41", manager.Snapshots()[0].Status.Lobby.ID)
+
+	cancel()
+	require.NoError(t, manager.Close(t.Context()))
+}
+
+func TestManagerReplay(t *testing.T) {
+	server, errServer := srcdstest.NewServer(srcdstest.Options{Password: "secret", LogSecret: 1234})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	// The live server has a player of its own, which must not be mixed in with the replayed players.
+	server.SetPlayers(srcdstest.Player{UserID: 9, Name: "Live Player", SteamID: steamid.New("[U:1:9]")})
+
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	logPath := filepath.Join(t.TempDir(), "server.log")
+	require.NoError(t, os.WriteFile(logPath, []byte(
+		`L 08/16/2025 - 01:00:00: "Player One<2><[U:1:2]><Unassigned>" joined team "Red"`+"\n"), 0o600))
+
+	logAddress := freeUDPAddress(t)
+	conf := config.Config{
+		ServerModeEnabled: true,
+		ServerLogAddress:  logAddress,
+		ServerBindAddress: logAddress,
+		Servers:           []config.ServerConfig{{Address: server.Address(), Password: "secret", LogSecret: 1234}},
+	}
+
+	router := events.NewRouter()
+	manager, errManager := state.NewManager(router, conf, nil, bd.New(nil, nil, nil), database)
+	require.NoError(t, errManager)
+
+	manager.SetReplay(console.NewReplay(console.MaxSpeed, nil,
+		console.ReplayFile{HostPort: server.Address(), Path: logPath}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		if err := manager.Start(ctx, router); err != nil {
+			t.Errorf("failed to start manager: %v", err)
+		}
+	}()
+
+	require.Eventually(t, func() bool {
+		players := manager.Snapshots()[0].Players
+
+		return len(players) == 1 && players[0].Name == "Player One" && players[0].Team == tf.RED
+	}, time.Second*5, time.Millisecond*50)
+
+	// Wait for a few dump ticks, which would otherwise poll the live server.
+	time.Sleep(time.Second * 3)
+	require.Len(t, manager.Snapshots()[0].Players, 1)
+
+	cancel()
+	require.NoError(t, manager.Close(t.Context()))
+	require.Empty(t, server.Commands())
+}
+
+func TestManagerReplayBlackBox(t *testing.T) {
+	server, errServer := srcdstest.NewServer(srcdstest.Options{Password: "secret"})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	// Chat and kills in the client console only include names, which must be resolved using the status output
+	// as there is no player dump while replaying.
+	logPath := filepath.Join(t.TempDir(), "console.log")
+	require.NoError(t, os.WriteFile(logPath, []byte(strings.Join([]string{
+		`08/16/2025 - 01:00:00: # 2 "Player One"  [U:1:2]  01:00  50  0 active`,
+		`08/16/2025 - 01:00:00: # 3 "Player Two"  [U:1:3]  01:00  60  0 active`,
+		`08/16/2025 - 01:00:05: Player Two :  hello`,
+		`08/16/2025 - 01:00:10: Player One killed Player Two with scattergun.`,
+		`08/16/2025 - 01:00:20: Player Two killed Player One with rocketlauncher.`,
+		`08/16/2025 - 01:00:30: Player Two killed Player One with rocketlauncher.`,
+	}, "\n")+"\n"), 0o600))
+
+	conf := config.Config{
+		SteamID:        steamid.New("[U:1:2]"),
+		ConsoleLogPath: logPath,
+		Client:         config.ServerConfig{Address: server.Address(), Password: "secret"},
+	}
+
+	router := events.NewRouter()
+	manager, errManager := state.NewManager(router, conf, nil, bd.New(nil, nil, nil), database)
+	require.NoError(t, errManager)
+
+	manager.SetReplay(console.NewReplay(console.MaxSpeed, nil,
+		console.ReplayFile{HostPort: server.Address(), Path: logPath}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		if err := manager.Start(ctx, router); err != nil {
+			t.Errorf("failed to start manager: %v", err)
+		}
+	}()
+
+	var (
+		queries = store.New(database)
+		victim  = int64(76561197960265731)
+	)
+
+	require.Eventually(t, func() bool {
+		players, errPlayers := queries.GetPlayers(t.Context(), []int64{victim})
+
+		return errPlayers == nil && len(players) == 1 && players[0].KilledBy == 2
+	}, time.Second*5, time.Millisecond*50)
+
+	cancel()
+	require.NoError(t, manager.Close(t.Context()))
+
+	players, errPlayers := queries.GetPlayers(t.Context(), []int64{victim})
+	require.NoError(t, errPlayers)
+	require.Equal(t, int64(1), players[0].KillsAgainst)
+
+	chat, errChat := queries.GetChatHistory(t.Context(), victim)
+	require.NoError(t, errChat)
+	require.Len(t, chat, 1)
+	require.Equal(t, "hello", chat[0].Message)
+
+	// The match times come from the log rather than when it was replayed.
+	matches, errMatches := queries.GetMatches(t.Context(), 10)
+	require.NoError(t, errMatches)
+	require.Len(t, matches, 1)
+	require.Equal(t, time.Date(2025, 8, 16, 1, 0, 0, 0, time.Local).Unix(), matches[0].CreatedOn)
+	require.Equal(t, int64(30), matches[0].Duration)
+}
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"strings"
 	"time"
 
 	"github.com/leighmacdonald/steamid/v4/steamid"
@@ -20,14 +21,60 @@ type Player struct {
 	Deaths        int
 	Connected     bool
 	Team          tf.Team
+	Class         tf.PlayerClass
 	Alive         bool
 	Health        int
 	Valid         bool
 	UserID        int
 	BDMatches     []bd.Match
+	Notes         string
+	Mark          Mark
+	KillsAgainst  int
+	KilledBy      int
+	Impersonates  steamid.SteamID
+	VoteKicks     int
 	Meta          tfapi.MetaProfile
 	MetaUpdatedOn time.Time
 	G15UpdatedOn  time.Time
+	// Pending is true for matchmaking lobby members that have not finished connecting.
+	Pending bool
 }
 
 type Players []Player
+
+// AllByName returns all players using the exact name. More than one result means that multiple players
+// share the name, which is usually the result of someone impersonating another player.
+func (p Players) AllByName(name string) Players {
+	var found Players
+
+	for _, player := range p {
+		if player.Name == name {
+			found = append(found, player)
+		}
+	}
+
+	return found
+}
+
+// Mark is a set of user defined tags, such as cheater or friend, attached to a player.
+type Mark struct {
+	Tags      []string
+	Reason    string
+	UpdatedOn time.Time
+}
+
+// Marked returns true when the player has been tagged by the user.
+func (m Mark) Marked() bool {
+	return len(m.Tags) > 0
+}
+
+func newMark(tags string, reason string, updatedOn int64) Mark {
+	mark := Mark{Reason: reason, UpdatedOn: time.Unix(updatedOn, 0)}
+	for tag := range strings.SplitSeq(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			mark.Tags = append(mark.Tags, tag)
+		}
+	}
+
+	return mark
+}
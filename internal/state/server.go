@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -24,8 +25,12 @@ var (
 	ErrPlayerNotFound = errors.New("player not found")
 	ErrRegistration   = errors.New("logaddress registration error")
 	ErrUnregistration = errors.New("logaddress unregistration error")
+	ErrPlayerDetails  = errors.New("failed to load player details")
 )
 
+// blackboxBuffer is the number of events queued for the blackbox while it is writing to the database.
+const blackboxBuffer = 1000
+
 type Snapshot struct {
 	HostPort    string
 	Players     Players
@@ -34,30 +39,48 @@ type Snapshot struct {
 	PluginsSM   []tf.GamePlugin
 	PluginsMeta []tf.GamePlugin
 	CVars       tf.CVarList
-	createdOn   time.Time
+	RCON        rcon.Health
+	// EventCount is the total number of log events received for the server.
+	EventCount int64
+	createdOn  time.Time
 }
 
 func newServerState(conf config.Config, server config.ServerConfig, router *events.Router, bdFetcher *bd.Fetcher,
-	dbConn store.DBTX,
+	dbConn store.DBTX, pool *rcon.Pool,
 ) *serverState {
+	// Queued by the router as the blackbox may block on database writes, and any events dropped would be
+	// missing from the recorded match.
 	allEvent := make(chan events.Event)
-	router.ListenFor(server.Address, allEvent, events.Any)
-	blackbox := newBlackBox(store.New(dbConn), allEvent)
-
-	serverEvents := make(chan events.Event)
-	router.ListenFor(server.Address, serverEvents, events.Any)
-
-	dumpFetcher := rcon.NewFetcher(server.Address, server.Password, conf.ServerModeEnabled)
+	blackboxSubscription := router.Subscribe(allEvent, events.SubscribeOpts{
+		Name:     "blackbox",
+		HostPort: server.Address,
+		Policy:   events.DeliverBuffered,
+		Buffer:   blackboxBuffer,
+	})
+	queries := store.New(dbConn)
+	blackbox := newBlackBox(queries, allEvent, conf.SteamID)
+
+	// Buffered as the router drops events for handlers that are not ready to receive them.
+	serverEvents := make(chan events.Event, 100)
+	serverSubscription := router.Subscribe(serverEvents, events.SubscribeOpts{Name: "server", HostPort: server.Address})
+
+	conn := pool.Get(server.Address, server.Password)
+	dumpFetcher := rcon.NewFetcher(conn, conf.ServerModeEnabled)
 
 	return &serverState{
 		mu:              &sync.RWMutex{},
 		server:          server,
 		blackbox:        blackbox,
+		db:              queries,
 		incomingEvents:  serverEvents,
+		subscriptions:   []*events.Subscription{blackboxSubscription, serverSubscription},
 		bdFetcher:       bdFetcher,
 		dumpFetcher:     dumpFetcher,
+		rcon:            conn,
 		externalAddress: conf.ServerLogAddress,
 		remote:          conf.ServerModeEnabled,
+		teams:           map[steamid.SteamID]tf.Team{},
+		classes:         map[steamid.SteamID]tf.PlayerClass{},
 	}
 }
 
@@ -69,9 +92,12 @@ type serverState struct {
 	remote          bool
 	externalAddress string
 	blackbox        *blackBox
+	db              *store.Queries
 	incomingEvents  chan events.Event
+	subscriptions   []*events.Subscription
 	bdFetcher       *bd.Fetcher
 	dumpFetcher     rcon.Fetcher
+	rcon            *rcon.Conn
 	status          tf.Status
 	countryCode     string
 	address         string
@@ -79,16 +105,29 @@ type serverState struct {
 	cvars           []tf.CVar
 	pluginsSM       []tf.GamePlugin
 	pluginsMeta     []tf.GamePlugin
+	// teams and classes are tracked from the log stream in server mode, as they are not available over rcon.
+	teams   map[steamid.SteamID]tf.Team
+	classes map[steamid.SteamID]tf.PlayerClass
+	// replay is set when events come from saved logs rather than the server, which is then never contacted.
+	replay bool
 }
 
 func (s *serverState) close(ctx context.Context) error {
+	for _, subscription := range s.subscriptions {
+		subscription.Cancel()
+	}
+
+	// Log addresses are only registered in server mode.
+	if !s.remote || s.replay {
+		return nil
+	}
+
 	return s.unregisterAddress(ctx)
 }
 
 func (s *serverState) unregisterAddress(ctx context.Context) error {
 	// Be cool and remove ourselves from the log address list.
-	conn := rcon.New(s.server.Address, s.server.Password)
-	if _, errExec := conn.Exec(ctx, "logaddress_del "+s.externalAddress, false); errExec != nil {
+	if _, errExec := s.rcon.Exec(ctx, "logaddress_del "+s.externalAddress, false); errExec != nil {
 		return errors.Join(errExec, ErrUnregistration)
 	}
 
@@ -113,51 +152,64 @@ func (s *serverState) resolveCountry(ctx context.Context) {
 	if errRecord != nil {
 		slog.Error("failed to lookup server country code", slog.String("error", errRecord.Error()))
 	} else {
+		s.mu.Lock()
 		s.countryCode = strings.ToLower(record.Country.ISOCode)
+		s.mu.Unlock()
 	}
 }
 
 func (s *serverState) fetchSMPluginsList(ctx context.Context) {
-	body, errData := rcon.New(s.server.Address, s.server.Password).Exec(ctx, "sm plugins list", true)
+	body, errData := s.rcon.Exec(ctx, "sm plugins list", true)
 	if errData != nil {
 		slog.Error("Failed to get sm plugins list", slog.String("error", errData.Error()))
 
 		return
 	}
 
-	s.pluginsSM = tf.ParseGamePlugins(body, false)
+	plugins := tf.ParseGamePlugins(body, false)
+
+	s.mu.Lock()
+	s.pluginsSM = plugins
+	s.mu.Unlock()
 }
 
 func (s *serverState) fetchMetaPluginsList(ctx context.Context) {
-	body, errData := rcon.New(s.server.Address, s.server.Password).Exec(ctx, "meta list", true)
+	body, errData := s.rcon.Exec(ctx, "meta list", true)
 	if errData != nil {
 		slog.Error("Failed to get meta list", slog.String("error", errData.Error()))
 
 		return
 	}
 
-	s.pluginsMeta = tf.ParseGamePlugins(body, false)
+	plugins := tf.ParseGamePlugins(body, false)
+
+	s.mu.Lock()
+	s.pluginsMeta = plugins
+	s.mu.Unlock()
 }
 
 func (s *serverState) fetchCVarList(ctx context.Context) {
-	cvarData, errData := rcon.New(s.server.Address, s.server.Password).Exec(ctx, "cvarlist", true)
+	cvarData, errData := s.rcon.Exec(ctx, "cvarlist", true)
 	if errData != nil {
 		slog.Error("Failed to get cvar list", slog.String("error", errData.Error()))
 
 		return
 	}
 
-	s.cvars = tf.ParseCVars(cvarData)
+	cvars := tf.ParseCVars(cvarData)
+
+	s.mu.Lock()
+	s.cvars = cvars
+	s.mu.Unlock()
 }
 
 func (s *serverState) registerAddress(ctx context.Context) error {
-	conn := rcon.New(s.server.Address, s.server.Password)
-	_, errExec := conn.Exec(ctx, "logaddress_add "+s.externalAddress, false)
+	_, errExec := s.rcon.Exec(ctx, "logaddress_add "+s.externalAddress, false)
 	if errExec != nil {
 		return errors.Join(errExec, ErrRegistration)
 	}
 
-	resp, err := conn.Exec(ctx, "logaddress_list", false)
+	resp, err := s.rcon.Exec(ctx, "logaddress_list", false)
 	if err != nil {
 		return errors.Join(err, ErrRegistration)
 	}
@@ -172,12 +224,14 @@ func (s *serverState) registerAddress(ctx context.Context) error {
 }
 
 func (s *serverState) start(ctx context.Context) error {
-	if s.remote {
+	if s.remote && !s.replay {
 		s.onStart(ctx)
 	}
 
-	// Start recording events.
-	go s.blackbox.Start(ctx)
+	// Start recording events. This is waited on so the final match is written before returning.
+	blackboxWait := &sync.WaitGroup{}
+	blackboxWait.Go(func() { s.blackbox.Start(ctx) })
+	defer blackboxWait.Wait()
 
 	removeTicker := time.NewTicker(removeInterval)
 	dumpTicker := time.NewTicker(checkInterval)
@@ -227,14 +281,14 @@ func (s *serverState) UpdateStatus(status tf.Status) {
 func (s *serverState) setPlayer(updates ...Player) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	var existing bool
 	for _, player := range updates {
+		existing := false
 		for playerIdx := range s.players {
 			if s.players[playerIdx].SteamID.Equal(player.SteamID) {
 				s.players[playerIdx] = player
 				existing = true
 
-				continue
+				break
 			}
 		}
 		if !existing {
@@ -244,7 +298,24 @@ func (s *serverState) setPlayer(updates ...Player) {
 }
 
 func (s *serverState) onIncomingEvent(event events.Event) {
+	s.eventCount.Add(1)
+
+	// Every srcds log line referencing a player includes their current team.
+	if s.remote {
+		for _, player := range events.ParseLogPlayers(event.Raw) {
+			if s.replay {
+				s.onReplayPlayer(player)
+			}
+
+			s.setTeam(player.PlayerSID, player.Team)
+		}
+	}
+
 	switch data := event.Data.(type) {
+	case events.JoinedTeamEvent:
+		s.setTeam(data.PlayerSID, data.NewTeam)
+	case events.ChangedRoleEvent:
+		s.setClass(data.PlayerSID, data.Class)
 	case events.AddressEvent:
 		s.onAddress(data.Address.String())
 	case events.ConnectEvent:
@@ -257,8 +328,6 @@ func (s *serverState) onIncomingEvent(event events.Event) {
 	case events.MsgEvent:
 	case events.TagsEvent:
 		// s.onTags(data.Tags)
-	case events.RawEvent:
-		s.eventCount.Add(1)
 	case events.StatusIDEvent:
 		s.onStatusID(data)
 	}
@@ -275,10 +344,76 @@ func (s *serverState) onStatusID(data events.StatusIDEvent) {
 	}
 
 	player.Name = data.Player
+	// g15_dumpplayer does not include the connection time in client mode, so this is the only source of it.
+	player.Time = data.Connected
+	if s.replay {
+		// There is no player dump while replaying, so this is the only indication that they are still connected.
+		player.G15UpdatedOn = time.Now()
+	}
+
+	s.setPlayer(player)
+	s.updateImpersonators()
+}
+
+// onReplayPlayer adds or refreshes a player seen in a replayed srcds log line, as status is not polled while
+// replaying server mode logs.
+func (s *serverState) onReplayPlayer(logPlayer events.LogPlayer) {
+	if !logPlayer.PlayerSID.Valid() {
+		return
+	}
+
+	player, errPlayer := s.player(logPlayer.PlayerSID)
+	if errPlayer != nil {
+		player = Player{SteamID: logPlayer.PlayerSID, Meta: tfapi.MetaProfile{Bans: []tfapi.Ban{}}}
+	}
+
+	player.Name = logPlayer.Player
+	player.UserID = logPlayer.UserID
+	player.G15UpdatedOn = time.Now()
 
 	s.setPlayer(player)
 }
 
+func (s *serverState) setTeam(steamID steamid.SteamID, team tf.Team) {
+	if !steamID.Valid() || team == tf.UNASSIGNED {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.teams[steamID] = team
+	for idx := range s.players {
+		if s.players[idx].SteamID.Equal(steamID) {
+			s.players[idx].Team = team
+		}
+	}
+}
+
+func (s *serverState) setClass(steamID steamid.SteamID, class tf.PlayerClass) {
+	if !steamID.Valid() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.classes[steamID] = class
+	for idx := range s.players {
+		if s.players[idx].SteamID.Equal(steamID) {
+			s.players[idx].Class = class
+		}
+	}
+}
+
+// logState returns the team and class of the player last seen in the logs.
+func (s *serverState) logState(steamID steamid.SteamID) (tf.Team, tf.PlayerClass) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.teams[steamID], s.classes[steamID]
+}
+
 func (s *serverState) onAddress(address string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -288,19 +423,124 @@ func (s *serverState) onAddress(address string) {
 
 func (s *serverState) onDumpTick(ctx context.Context) {
 	waitGroup := &sync.WaitGroup{}
-	waitGroup.Add(2)
+	waitGroup.Go(s.updateBD)
 
-	go func() {
-		defer waitGroup.Done()
-		s.updateBD()
-	}()
-
-	go func() {
-		defer waitGroup.Done()
-		s.updateDump(ctx)
-	}()
+	// The live server is not polled while replaying, as its players would be mixed in with the replayed ones.
+	if !s.replay {
+		waitGroup.Go(func() { s.updateDump(ctx) })
+	}
 
 	waitGroup.Wait()
+
+	s.updateImpersonators()
+
+	// Loaded after the other updates so newly seen players have their stored details attached.
+	steamIDs := s.steamIDs()
+	if len(steamIDs) == 0 {
+		return
+	}
+
+	if err := s.updateDetails(ctx, steamIDs); err != nil {
+		slog.Error("Failed to update player details", slog.String("error", err.Error()))
+	}
+}
+
+// updateDetails loads the stored notes, marks, kill counts and vote kicks of the players in a single query.
+// KillsAgainst is the number of times we have killed the player, KilledBy the number of times the player
+// has killed us. Players whose mark has been removed are cleared.
+func (s *serverState) updateDetails(ctx context.Context, steamIDs []int64) error {
+	rows, errRows := s.db.GetPlayerDetails(ctx, steamIDs)
+	if errRows != nil {
+		return errors.Join(errRows, ErrPlayerDetails)
+	}
+
+	details := make(map[int64]store.GetPlayerDetailsRow, len(rows))
+	for _, row := range rows {
+		details[row.SteamID] = row
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.players {
+		steamID := s.players[idx].SteamID.Int64()
+		if !slices.Contains(steamIDs, steamID) {
+			// Joined after the query was made, so will be loaded on the next tick.
+			continue
+		}
+
+		row, found := details[steamID]
+		if !found {
+			s.players[idx].Mark = Mark{}
+
+			continue
+		}
+
+		s.players[idx].Notes = row.Notes
+		s.players[idx].KillsAgainst = int(row.KillsAgainst)
+		s.players[idx].KilledBy = int(row.KilledBy)
+		s.players[idx].VoteKicks = int(row.VoteKicks)
+		s.players[idx].Mark = Mark{}
+		if row.MarkTags != "" {
+			s.players[idx].Mark = newMark(row.MarkTags, row.MarkNote, row.MarkUpdatedOn)
+		}
+	}
+
+	return nil
+}
+
+// updateImpersonators flags all players that are using a name visually identical to another player in the
+// server. Players that no longer share a name have the flag cleared.
+func (s *serverState) updateImpersonators() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	impersonators := findImpersonators(s.players)
+	for idx := range s.players {
+		target, found := impersonators[s.players[idx].SteamID]
+		if found && !s.players[idx].Impersonates.Equal(target) {
+			slog.Warn("Possible name stealer detected", slog.String("name", s.players[idx].Name),
+				slog.String("steam_id", s.players[idx].SteamID.String()), slog.String("target", target.String()))
+		}
+
+		s.players[idx].Impersonates = target
+	}
+}
+
+func (s *serverState) steamIDs() []int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	steamIDs := make([]int64, len(s.players))
+	for idx, player := range s.players {
+		steamIDs[idx] = player.SteamID.Int64()
+	}
+
+	return steamIDs
+}
+
+// setMark updates only the mark of a player, leaving the rest of the player state untouched.
+func (s *serverState) setMark(steamID steamid.SteamID, mark Mark) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.players {
+		if s.players[idx].SteamID.Equal(steamID) {
+			s.players[idx].Mark = mark
+		}
+	}
+}
+
+// setNotes updates only the notes of a player, leaving the rest of the player state untouched.
+func (s *serverState) setNotes(steamID steamid.SteamID, notes string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.players {
+		if s.players[idx].SteamID.Equal(steamID) {
+			s.players[idx].Notes = notes
+		}
+	}
 }
 
 func (s *serverState) player(steamID steamid.SteamID) (Player, error) {
@@ -316,17 +556,22 @@ func (s *serverState) player(steamID steamid.SteamID) (Player, error) {
 }
 
 func (s *serverState) Snapshot() Snapshot {
+	// Read before taking the lock so that writers are never held up by the rcon connection.
+	health := s.rcon.Health()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	return Snapshot{
 		HostPort:    s.server.Address,
-		Players:     s.players,
+		Players:     slices.Clone(s.players),
 		Status:      s.status,
 		Region:      s.countryCode,
 		PluginsSM:   s.pluginsSM,
 		PluginsMeta: s.pluginsMeta,
 		CVars:       s.cvars,
+		RCON:        health,
+		EventCount:  s.eventCount.Load(),
 		createdOn:   time.Now(),
 	}
 }
@@ -345,6 +590,38 @@ func (s *serverState) updateDump(ctx context.Context) {
 
 	s.UpdateStatus(status)
 	s.UpdateDumpPlayer(dump)
+	s.updateLobby(status.Lobby)
+}
+
+// updateLobby adds the members of the matchmaking lobby that are not yet in the game, so that they can be
+// checked against the bot detector lists before they have finished connecting. Members that have joined
+// are kept up to date by UpdateDumpPlayer instead.
+func (s *serverState) updateLobby(lobby tf.Lobby) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.players {
+		s.players[idx].Pending = false
+	}
+
+	for _, member := range lobby.Members {
+		idx := slices.IndexFunc(s.players, func(player Player) bool {
+			return player.SteamID.Equal(member.SteamID)
+		})
+		if idx < 0 {
+			s.players = append(s.players, Player{SteamID: member.SteamID, Meta: tfapi.MetaProfile{Bans: []tfapi.Ban{}}})
+			idx = len(s.players) - 1
+		}
+
+		player := &s.players[idx]
+		player.Pending = member.Pending
+		if player.Team == tf.UNASSIGNED {
+			player.Team = member.Team
+		}
+		// Members still connecting are not in the player dump, this stops them from expiring until they leave
+		// the lobby.
+		player.G15UpdatedOn = time.Now()
+	}
 }
 
 func (s *serverState) UpdateMetaProfile(metaProfiles ...tfapi.MetaProfile) {
@@ -369,6 +646,9 @@ func (s *serverState) removeExpired() {
 	var valid Players
 	for _, player := range s.players {
 		if time.Since(player.G15UpdatedOn) > playerTimeout {
+			delete(s.teams, player.SteamID)
+			delete(s.classes, player.SteamID)
+
 			continue
 		}
 
@@ -406,12 +686,18 @@ func (s *serverState) UpdateDumpPlayer(stats tf.DumpPlayer) {
 		player.Name = stats.Names[idx]
 		player.Loss = stats.Loss[idx]
 		player.Address = stats.Address[idx]
-		player.Time = stats.Time[idx]
+		if stats.Time[idx] > 0 {
+			player.Time = stats.Time[idx]
+		}
 		player.Team = stats.Team[idx]
+		if s.remote {
+			player.Team, player.Class = s.logState(sid)
+		}
 		player.UserID = stats.UserID[idx]
 		player.G15UpdatedOn = time.Now()
 		players = append(players, player)
 	}
 
 	s.setPlayer(players...)
+	s.blackbox.syncPlayers(players)
 }
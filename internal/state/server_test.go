@@ -0,0 +1,25 @@
+package state
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPlayer(t *testing.T) {
+	server := &serverState{mu: &sync.RWMutex{}}
+
+	// Every new player must be added, not only those before the first existing one.
+	server.setPlayer(Player{SteamID: steamid.New("[U:1:1]"), Name: "Player One"})
+	server.setPlayer(
+		Player{SteamID: steamid.New("[U:1:1]"), Name: "Player Renamed"},
+		Player{SteamID: steamid.New("[U:1:2]"), Name: "Player Two"},
+		Player{SteamID: steamid.New("[U:1:3]"), Name: "Player Three"},
+	)
+
+	require.Len(t, server.players, 3)
+	require.Equal(t, "Player Renamed", server.players[0].Name)
+	require.Equal(t, "Player Three", server.players[2].Name)
+}
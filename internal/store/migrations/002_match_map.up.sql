@@ -0,0 +1 @@
+ALTER TABLE match ADD COLUMN map_name TEXT NOT NULL DEFAULT '';
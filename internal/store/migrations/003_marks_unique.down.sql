@@ -0,0 +1 @@
+DROP INDEX IF EXISTS marks_steam_id_uindex;
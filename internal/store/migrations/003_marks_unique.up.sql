@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS marks_steam_id_uindex ON marks (steam_id);
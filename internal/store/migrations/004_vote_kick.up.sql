@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS vote_kick (
+    vote_kick_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    steam_id BIGINT NOT NULL,
+    user_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    address TEXT NOT NULL,
+    created_on INTEGER NOT NULL,
+    FOREIGN KEY(steam_id) REFERENCES player(steam_id)
+);
@@ -28,6 +28,7 @@ type Match struct {
 	Address   string
 	Duration  int64
 	CreatedOn int64
+	MapName   string
 }
 
 type MatchPlayer struct {
@@ -53,3 +54,12 @@ type Player struct {
 	CreatedOn    int64
 	UpdatedOn    int64
 }
+
+type VoteKick struct {
+	VoteKickID int64
+	SteamID    int64
+	UserID     int64
+	Reason     string
+	Address    string
+	CreatedOn  int64
+}
@@ -2,8 +2,8 @@
 INSERT INTO player (steam_id, name, created_on, updated_on)
 VALUES (sqlc.arg(steam_id), sqlc.arg(name), sqlc.arg(created_on), sqlc.arg(updated_on))
 ON CONFLICT (steam_id) DO UPDATE
-    SET name       = sqlc.arg(name),
-        updated_on = sqlc.arg(updated_on);
+    SET name       = COALESCE(NULLIF(excluded.name, ''), player.name),
+        updated_on = excluded.updated_on;
 
 -- name: GetNotes :many
 SELECT *
@@ -21,7 +21,7 @@ SET note       = ?,
 WHERE steam_id = ?;
 
 -- name: InsertChat :exec
-INSERT INTO chat_history (chat_id, steam_id, name, message, team_only, created_on)
+INSERT INTO chat_history (match_id, steam_id, name, message, team_only, created_on)
 VALUES (?, ?, ?, ?, ?, ?);
 
 -- name: GetChatHistory :many
@@ -31,4 +31,92 @@ WHERE steam_id = ?;
 
 -- name: InsertMark :exec
 INSERT INTO marks (steam_id, tags, note, created_on, updated_on)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (steam_id) DO UPDATE
+    SET tags       = excluded.tags,
+        note       = excluded.note,
+        updated_on = excluded.updated_on;
+
+-- name: ListMarks :many
+SELECT m.steam_id, p.name, m.tags, m.note, m.created_on, m.updated_on
+FROM marks m
+         INNER JOIN player p ON p.steam_id = m.steam_id
+ORDER BY m.updated_on DESC;
+
+-- name: DeleteMark :exec
+DELETE
+FROM marks
+WHERE steam_id = ?;
+
+-- name: InsertMatch :one
+INSERT INTO match (hostname, address, map_name, duration, created_on)
+VALUES (?, ?, ?, ?, ?)
+RETURNING match_id;
+
+-- name: UpdateMatch :exec
+UPDATE match
+SET hostname = ?,
+    address  = ?,
+    map_name = ?,
+    duration = ?
+WHERE match_id = ?;
+
+-- name: InsertMatchPlayer :exec
+INSERT INTO match_player (match_id, steam_id, score, deaths, ping, connected)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetMatches :many
+SELECT m.match_id,
+       m.hostname,
+       m.address,
+       m.map_name,
+       m.duration,
+       m.created_on,
+       COUNT(mp.steam_id) AS player_count
+FROM match m
+         LEFT JOIN match_player mp ON mp.match_id = m.match_id
+GROUP BY m.match_id
+ORDER BY m.created_on DESC
+LIMIT ?;
+
+-- name: GetMatchPlayers :many
+SELECT mp.steam_id, p.name, mp.score, mp.deaths, mp.ping, mp.connected
+FROM match_player mp
+         INNER JOIN player p ON p.steam_id = mp.steam_id
+WHERE mp.match_id = ?
+ORDER BY mp.score DESC;
+
+-- name: GetMatchChat :many
+SELECT *
+FROM chat_history
+WHERE match_id = ?
+ORDER BY created_on;
+
+-- name: GetPlayers :many
+SELECT *
+FROM player
+WHERE steam_id IN (sqlc.slice(steam_ids));
+
+-- name: UpdatePlayerKills :exec
+UPDATE player
+SET kills_against = kills_against + ?,
+    killed_by     = killed_by + ?
+WHERE steam_id = ?;
+
+-- name: InsertVoteKick :exec
+INSERT INTO vote_kick (steam_id, user_id, reason, address, created_on)
 VALUES (?, ?, ?, ?, ?);
+
+-- name: GetPlayerDetails :many
+SELECT p.steam_id,
+       p.kills_against,
+       p.killed_by,
+       COALESCE(n.note, '')                                            AS notes,
+       COALESCE(m.tags, '')                                            AS mark_tags,
+       COALESCE(m.note, '')                                            AS mark_note,
+       COALESCE(m.updated_on, 0)                                       AS mark_updated_on,
+       (SELECT count(*) FROM vote_kick v WHERE v.steam_id = p.steam_id) AS vote_kicks
+FROM player p
+         LEFT JOIN notes n ON n.steam_id = p.steam_id
+         LEFT JOIN marks m ON m.steam_id = p.steam_id
+WHERE p.steam_id IN (sqlc.slice(steam_ids));
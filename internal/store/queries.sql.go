@@ -10,6 +10,17 @@ import (
 	"strings"
 )
 
+const deleteMark = `-- name: DeleteMark :exec
+DELETE
+FROM marks
+WHERE steam_id = ?
+`
+
+func (q *Queries) DeleteMark(ctx context.Context, steamID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteMark, steamID)
+	return err
+}
+
 const getChatHistory = `-- name: GetChatHistory :many
 SELECT chat_id, match_id, steam_id, name, message, team_only, created_on
 FROM chat_history
@@ -47,6 +58,147 @@ func (q *Queries) GetChatHistory(ctx context.Context, steamID int64) ([]ChatHist
 	return items, nil
 }
 
+const getMatchChat = `-- name: GetMatchChat :many
+SELECT chat_id, match_id, steam_id, name, message, team_only, created_on
+FROM chat_history
+WHERE match_id = ?
+ORDER BY created_on
+`
+
+func (q *Queries) GetMatchChat(ctx context.Context, matchID int64) ([]ChatHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getMatchChat, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChatHistory
+	for rows.Next() {
+		var i ChatHistory
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.MatchID,
+			&i.SteamID,
+			&i.Name,
+			&i.Message,
+			&i.TeamOnly,
+			&i.CreatedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMatchPlayers = `-- name: GetMatchPlayers :many
+SELECT mp.steam_id, p.name, mp.score, mp.deaths, mp.ping, mp.connected
+FROM match_player mp
+         INNER JOIN player p ON p.steam_id = mp.steam_id
+WHERE mp.match_id = ?
+ORDER BY mp.score DESC
+`
+
+type GetMatchPlayersRow struct {
+	SteamID   int64
+	Name      string
+	Score     int64
+	Deaths    int64
+	Ping      int64
+	Connected int64
+}
+
+func (q *Queries) GetMatchPlayers(ctx context.Context, matchID int64) ([]GetMatchPlayersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMatchPlayers, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMatchPlayersRow
+	for rows.Next() {
+		var i GetMatchPlayersRow
+		if err := rows.Scan(
+			&i.SteamID,
+			&i.Name,
+			&i.Score,
+			&i.Deaths,
+			&i.Ping,
+			&i.Connected,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMatches = `-- name: GetMatches :many
+SELECT m.match_id,
+       m.hostname,
+       m.address,
+       m.map_name,
+       m.duration,
+       m.created_on,
+       COUNT(mp.steam_id) AS player_count
+FROM match m
+         LEFT JOIN match_player mp ON mp.match_id = m.match_id
+GROUP BY m.match_id
+ORDER BY m.created_on DESC
+LIMIT ?
+`
+
+type GetMatchesRow struct {
+	MatchID     int64
+	Hostname    string
+	Address     string
+	MapName     string
+	Duration    int64
+	CreatedOn   int64
+	PlayerCount int64
+}
+
+func (q *Queries) GetMatches(ctx context.Context, limit int64) ([]GetMatchesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMatches, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMatchesRow
+	for rows.Next() {
+		var i GetMatchesRow
+		if err := rows.Scan(
+			&i.MatchID,
+			&i.Hostname,
+			&i.Address,
+			&i.MapName,
+			&i.Duration,
+			&i.CreatedOn,
+			&i.PlayerCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNotes = `-- name: GetNotes :many
 SELECT steam_id, note, updated_on
 FROM notes
@@ -86,13 +238,127 @@ func (q *Queries) GetNotes(ctx context.Context, steamIds []int64) ([]Note, error
 	return items, nil
 }
 
+const getPlayerDetails = `-- name: GetPlayerDetails :many
+SELECT p.steam_id,
+       p.kills_against,
+       p.killed_by,
+       COALESCE(n.note, '')                                            AS notes,
+       COALESCE(m.tags, '')                                            AS mark_tags,
+       COALESCE(m.note, '')                                            AS mark_note,
+       COALESCE(m.updated_on, 0)                                       AS mark_updated_on,
+       (SELECT count(*) FROM vote_kick v WHERE v.steam_id = p.steam_id) AS vote_kicks
+FROM player p
+         LEFT JOIN notes n ON n.steam_id = p.steam_id
+         LEFT JOIN marks m ON m.steam_id = p.steam_id
+WHERE p.steam_id IN (/*SLICE:steam_ids*/?)
+`
+
+type GetPlayerDetailsRow struct {
+	SteamID       int64
+	KillsAgainst  int64
+	KilledBy      int64
+	Notes         string
+	MarkTags      string
+	MarkNote      string
+	MarkUpdatedOn int64
+	VoteKicks     int64
+}
+
+func (q *Queries) GetPlayerDetails(ctx context.Context, steamIds []int64) ([]GetPlayerDetailsRow, error) {
+	query := getPlayerDetails
+	var queryParams []interface{}
+	if len(steamIds) > 0 {
+		for _, v := range steamIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:steam_ids*/?", strings.Repeat(",?", len(steamIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:steam_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPlayerDetailsRow
+	for rows.Next() {
+		var i GetPlayerDetailsRow
+		if err := rows.Scan(
+			&i.SteamID,
+			&i.KillsAgainst,
+			&i.KilledBy,
+			&i.Notes,
+			&i.MarkTags,
+			&i.MarkNote,
+			&i.MarkUpdatedOn,
+			&i.VoteKicks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlayers = `-- name: GetPlayers :many
+SELECT steam_id, name, kills_against, killed_by, created_on, updated_on
+FROM player
+WHERE steam_id IN (/*SLICE:steam_ids*/?)
+`
+
+func (q *Queries) GetPlayers(ctx context.Context, steamIds []int64) ([]Player, error) {
+	query := getPlayers
+	var queryParams []interface{}
+	if len(steamIds) > 0 {
+		for _, v := range steamIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:steam_ids*/?", strings.Repeat(",?", len(steamIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:steam_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Player
+	for rows.Next() {
+		var i Player
+		if err := rows.Scan(
+			&i.SteamID,
+			&i.Name,
+			&i.KillsAgainst,
+			&i.KilledBy,
+			&i.CreatedOn,
+			&i.UpdatedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertChat = `-- name: InsertChat :exec
-INSERT INTO chat_history (chat_id, steam_id, name, message, team_only, created_on)
+INSERT INTO chat_history (match_id, steam_id, name, message, team_only, created_on)
 VALUES (?, ?, ?, ?, ?, ?)
 `
 
 type InsertChatParams struct {
-	ChatID    int64
+	MatchID   int64
 	SteamID   int64
 	Name      string
 	Message   string
@@ -102,7 +368,7 @@ type InsertChatParams struct {
 
 func (q *Queries) InsertChat(ctx context.Context, arg InsertChatParams) error {
 	_, err := q.db.ExecContext(ctx, insertChat,
-		arg.ChatID,
+		arg.MatchID,
 		arg.SteamID,
 		arg.Name,
 		arg.Message,
@@ -115,6 +381,10 @@ func (q *Queries) InsertChat(ctx context.Context, arg InsertChatParams) error {
 const insertMark = `-- name: InsertMark :exec
 INSERT INTO marks (steam_id, tags, note, created_on, updated_on)
 VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (steam_id) DO UPDATE
+    SET tags       = excluded.tags,
+        note       = excluded.note,
+        updated_on = excluded.updated_on
 `
 
 type InsertMarkParams struct {
@@ -136,6 +406,59 @@ func (q *Queries) InsertMark(ctx context.Context, arg InsertMarkParams) error {
 	return err
 }
 
+const insertMatch = `-- name: InsertMatch :one
+INSERT INTO match (hostname, address, map_name, duration, created_on)
+VALUES (?, ?, ?, ?, ?)
+RETURNING match_id
+`
+
+type InsertMatchParams struct {
+	Hostname  string
+	Address   string
+	MapName   string
+	Duration  int64
+	CreatedOn int64
+}
+
+func (q *Queries) InsertMatch(ctx context.Context, arg InsertMatchParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, insertMatch,
+		arg.Hostname,
+		arg.Address,
+		arg.MapName,
+		arg.Duration,
+		arg.CreatedOn,
+	)
+	var match_id int64
+	err := row.Scan(&match_id)
+	return match_id, err
+}
+
+const insertMatchPlayer = `-- name: InsertMatchPlayer :exec
+INSERT INTO match_player (match_id, steam_id, score, deaths, ping, connected)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type InsertMatchPlayerParams struct {
+	MatchID   int64
+	SteamID   int64
+	Score     int64
+	Deaths    int64
+	Ping      int64
+	Connected int64
+}
+
+func (q *Queries) InsertMatchPlayer(ctx context.Context, arg InsertMatchPlayerParams) error {
+	_, err := q.db.ExecContext(ctx, insertMatchPlayer,
+		arg.MatchID,
+		arg.SteamID,
+		arg.Score,
+		arg.Deaths,
+		arg.Ping,
+		arg.Connected,
+	)
+	return err
+}
+
 const insertNote = `-- name: InsertNote :exec
 INSERT INTO notes (steam_id, note, updated_on)
 VALUES (?, ?, ?)
@@ -156,8 +479,8 @@ const insertPlayer = `-- name: InsertPlayer :exec
 INSERT INTO player (steam_id, name, created_on, updated_on)
 VALUES (?1, ?2, ?3, ?4)
 ON CONFLICT (steam_id) DO UPDATE
-    SET name       = sqlc.arg(name),
-        updated_on = sqlc.arg(updated_on)
+    SET name       = COALESCE(NULLIF(excluded.name, ''), player.name),
+        updated_on = excluded.updated_on
 `
 
 type InsertPlayerParams struct {
@@ -177,6 +500,104 @@ func (q *Queries) InsertPlayer(ctx context.Context, arg InsertPlayerParams) erro
 	return err
 }
 
+const insertVoteKick = `-- name: InsertVoteKick :exec
+INSERT INTO vote_kick (steam_id, user_id, reason, address, created_on)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type InsertVoteKickParams struct {
+	SteamID   int64
+	UserID    int64
+	Reason    string
+	Address   string
+	CreatedOn int64
+}
+
+func (q *Queries) InsertVoteKick(ctx context.Context, arg InsertVoteKickParams) error {
+	_, err := q.db.ExecContext(ctx, insertVoteKick,
+		arg.SteamID,
+		arg.UserID,
+		arg.Reason,
+		arg.Address,
+		arg.CreatedOn,
+	)
+	return err
+}
+
+const listMarks = `-- name: ListMarks :many
+SELECT m.steam_id, p.name, m.tags, m.note, m.created_on, m.updated_on
+FROM marks m
+         INNER JOIN player p ON p.steam_id = m.steam_id
+ORDER BY m.updated_on DESC
+`
+
+type ListMarksRow struct {
+	SteamID   int64
+	Name      string
+	Tags      string
+	Note      string
+	CreatedOn int64
+	UpdatedOn int64
+}
+
+func (q *Queries) ListMarks(ctx context.Context) ([]ListMarksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMarks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMarksRow
+	for rows.Next() {
+		var i ListMarksRow
+		if err := rows.Scan(
+			&i.SteamID,
+			&i.Name,
+			&i.Tags,
+			&i.Note,
+			&i.CreatedOn,
+			&i.UpdatedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateMatch = `-- name: UpdateMatch :exec
+UPDATE match
+SET hostname = ?,
+    address  = ?,
+    map_name = ?,
+    duration = ?
+WHERE match_id = ?
+`
+
+type UpdateMatchParams struct {
+	Hostname string
+	Address  string
+	MapName  string
+	Duration int64
+	MatchID  int64
+}
+
+func (q *Queries) UpdateMatch(ctx context.Context, arg UpdateMatchParams) error {
+	_, err := q.db.ExecContext(ctx, updateMatch,
+		arg.Hostname,
+		arg.Address,
+		arg.MapName,
+		arg.Duration,
+		arg.MatchID,
+	)
+	return err
+}
+
 const updateNote = `-- name: UpdateNote :exec
 UPDATE notes
 SET note       = ?,
@@ -194,3 +615,21 @@ func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) error {
 	_, err := q.db.ExecContext(ctx, updateNote, arg.Note, arg.UpdatedOn, arg.SteamID)
 	return err
 }
+
+const updatePlayerKills = `-- name: UpdatePlayerKills :exec
+UPDATE player
+SET kills_against = kills_against + ?,
+    killed_by     = killed_by + ?
+WHERE steam_id = ?
+`
+
+type UpdatePlayerKillsParams struct {
+	KillsAgainst int64
+	KilledBy     int64
+	SteamID      int64
+}
+
+func (q *Queries) UpdatePlayerKills(ctx context.Context, arg UpdatePlayerKillsParams) error {
+	_, err := q.db.ExecContext(ctx, updatePlayerKills, arg.KillsAgainst, arg.KilledBy, arg.SteamID)
+	return err
+}
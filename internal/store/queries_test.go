@@ -0,0 +1,82 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/leighmacdonald/tf-tui/internal/store"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestInsertPlayerKeepsName(t *testing.T) {
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	queries := store.New(database)
+	const steamID = 76561197960265729
+
+	for _, testCase := range []struct {
+		name     string
+		expected string
+	}{
+		{"Player One", "Player One"},
+		// Players not currently in a server are saved without a name, which must not replace the known one.
+		{"", "Player One"},
+		{"Player Renamed", "Player Renamed"},
+	} {
+		require.NoError(t, queries.InsertPlayer(t.Context(), store.InsertPlayerParams{
+			SteamID:   steamID,
+			Name:      testCase.name,
+			CreatedOn: 1,
+			UpdatedOn: 2,
+		}))
+
+		players, errPlayers := queries.GetPlayers(t.Context(), []int64{steamID})
+		require.NoError(t, errPlayers)
+		require.Len(t, players, 1)
+		require.Equal(t, testCase.expected, players[0].Name)
+	}
+}
+
+func TestGetPlayerDetails(t *testing.T) {
+	database, errDB := store.Open(t.Context(), filepath.Join(t.TempDir(), "test.db"), true)
+	require.NoError(t, errDB)
+	t.Cleanup(func() { _ = database.Close() })
+
+	queries := store.New(database)
+	const (
+		marked   = 76561197960265729
+		unmarked = 76561197960265730
+		unknown  = 76561197960265731
+	)
+
+	for _, steamID := range []int64{marked, unmarked} {
+		require.NoError(t, queries.InsertPlayer(t.Context(), store.InsertPlayerParams{SteamID: steamID, Name: "Player"}))
+	}
+
+	require.NoError(t, queries.InsertNote(t.Context(), store.InsertNoteParams{SteamID: marked, Note: "notes", UpdatedOn: 1}))
+	require.NoError(t, queries.InsertMark(t.Context(), store.InsertMarkParams{
+		SteamID: marked, Tags: "cheater", Note: "reason", CreatedOn: 1, UpdatedOn: 2,
+	}))
+	require.NoError(t, queries.UpdatePlayerKills(t.Context(), store.UpdatePlayerKillsParams{
+		KillsAgainst: 3, KilledBy: 4, SteamID: marked,
+	}))
+
+	for range 2 {
+		require.NoError(t, queries.InsertVoteKick(t.Context(), store.InsertVoteKickParams{
+			SteamID: marked, Reason: "cheating", Address: "127.0.0.1:27015", CreatedOn: 1,
+		}))
+	}
+
+	rows, errRows := queries.GetPlayerDetails(t.Context(), []int64{marked, unmarked, unknown})
+	require.NoError(t, errRows)
+	require.ElementsMatch(t, []store.GetPlayerDetailsRow{
+		{
+			SteamID: marked, KillsAgainst: 3, KilledBy: 4, Notes: "notes",
+			MarkTags: "cheater", MarkNote: "reason", MarkUpdatedOn: 2, VoteKicks: 2,
+		},
+		{SteamID: unmarked},
+	}, rows)
+}
@@ -0,0 +1,111 @@
+package console
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxCaptureLine is the largest capture line that can be read. Packets are at most 1024 bytes, which is
+// roughly 1.4KB once base64 encoded.
+const maxCaptureLine = 64 * 1024
+
+// CapturedPacket is a single raw log packet as received by Remote. Captures are stored as one json encoded
+// packet per line so that they can be appended to, and inspected with standard tools.
+type CapturedPacket struct {
+	// Time is when the packet arrived.
+	Time time.Time `json:"time"`
+	// Source is the address the packet was sent from.
+	Source string `json:"source"`
+	// Data is the packet exactly as received, including the header and packet type.
+	Data []byte `json:"data"`
+}
+
+// captureWriter appends packets to a capture file.
+type captureWriter struct {
+	mu      *sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func newCaptureWriter(path string) (*captureWriter, error) {
+	file, errOpen := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if errOpen != nil {
+		return nil, errOpen
+	}
+
+	return &captureWriter{mu: &sync.Mutex{}, file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (w *captureWriter) Write(source string, packet []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.encoder.Encode(CapturedPacket{Time: time.Now(), Source: source, Data: packet})
+}
+
+func (w *captureWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
+
+// ReadCapture reads all packets from a capture written by Remote.
+func ReadCapture(reader io.Reader) ([]CapturedPacket, error) {
+	var (
+		packets []CapturedPacket
+		scanner = bufio.NewScanner(reader)
+	)
+
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxCaptureLine)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var packet CapturedPacket
+		if err := json.Unmarshal(scanner.Bytes(), &packet); err != nil {
+			return nil, errors.Join(err, ErrRead)
+		}
+
+		packets = append(packets, packet)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(err, ErrRead)
+	}
+
+	return packets, nil
+}
+
+// isCapture checks if the first line of a file is a captured packet, rather than a plain log line.
+func isCapture(path string) (bool, error) {
+	file, errOpen := os.Open(path)
+	if errOpen != nil {
+		return false, errOpen
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxCaptureLine)
+
+	if !scanner.Scan() {
+		return false, scanner.Err()
+	}
+
+	var packet CapturedPacket
+	if err := json.Unmarshal(scanner.Bytes(), &packet); err != nil {
+		return false, nil //nolint:nilerr
+	}
+
+	return len(packet.Data) > 0, nil
+}
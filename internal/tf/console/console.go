@@ -23,4 +23,6 @@ var (
 	ErrSetup  = errors.New("failed to setup log source")
 	ErrClose  = errors.New("failed to close log source")
 	ErrConfig = errors.New("config error")
+	ErrPacket = errors.New("malformed log packet")
+	ErrRead   = errors.New("failed to read capture")
 )
@@ -9,11 +9,14 @@ import (
 	"github.com/nxadm/tail"
 )
 
-func NewLocal(filePath string) *Local {
+// NewLocal creates a new console.log reader. The hostPort is used to identify the events
+// as belonging to the local client server.
+func NewLocal(hostPort string, filePath string) *Local {
 	return &Local{
 		tail:     nil,
 		stopChan: make(chan any),
 		filePath: filePath,
+		hostPort: hostPort,
 	}
 }
 
@@ -23,6 +26,7 @@ type Local struct {
 	tail     *tail.Tail
 	stopChan chan any
 	filePath string
+	hostPort string
 }
 
 func (l *Local) Close(_ context.Context) error {
@@ -30,7 +34,11 @@ func (l *Local) Close(_ context.Context) error {
 		return nil
 	}
 
-	l.stopChan <- "ahh!"
+	// Start may have already returned after its context was cancelled, so don't wait for it.
+	select {
+	case l.stopChan <- "ahh!":
+	default:
+	}
 
 	return nil
 }
@@ -92,7 +100,7 @@ func (l *Local) Start(ctx context.Context, receiver Receiver) {
 
 			slog.Debug("Log line", slog.String("src", "local"), slog.String("line", msg.Text))
 
-			receiver.Send("", msg.Text)
+			receiver.Send(l.hostPort, msg.Text)
 		case <-l.stopChan:
 			stop()
 
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,11 +31,19 @@ type Remote struct {
 	listenAddress string
 	// Maps log_secret to host:port identifier.
 	ServerHostMap map[int]string
+	countsMu      *sync.Mutex
+	// packetCounts is the number of packets received for each log secret. Packets without a secret are
+	// counted under 0.
+	packetCounts map[int]int64
+	capturePath  string
+	capture      *captureWriter
 }
 
 type RemoteOpts struct {
 	ListenAddress string
 	ServerHostMap map[int]string
+	// CapturePath is an optional file that all raw log packets are appended to. See ReadCapture.
+	CapturePath string
 }
 
 func NewRemote(opts RemoteOpts) (*Remote, error) {
@@ -42,7 +52,21 @@ func NewRemote(opts RemoteOpts) (*Remote, error) {
 		return nil, ErrConfig
 	}
 
-	return &Remote{listenAddress: opts.ListenAddress, ServerHostMap: opts.ServerHostMap}, nil
+	return &Remote{
+		listenAddress: opts.ListenAddress,
+		ServerHostMap: opts.ServerHostMap,
+		countsMu:      &sync.Mutex{},
+		packetCounts:  map[int]int64{},
+		capturePath:   opts.CapturePath,
+	}, nil
+}
+
+// PacketCounts returns the total number of log packets received for each log secret.
+func (l *Remote) PacketCounts() map[int]int64 {
+	l.countsMu.Lock()
+	defer l.countsMu.Unlock()
+
+	return maps.Clone(l.packetCounts)
 }
 
 func (l *Remote) Close(_ context.Context) error {
@@ -53,6 +77,12 @@ func (l *Remote) Close(_ context.Context) error {
 		}
 	}
 
+	if l.capture != nil {
+		if errCaptureClose := l.capture.Close(); errCaptureClose != nil {
+			err = errors.Join(err, errCaptureClose)
+		}
+	}
+
 	if err != nil {
 		return errors.Join(err, ErrClose)
 	}
@@ -71,6 +101,17 @@ func (l *Remote) Open() error {
 		return errors.Join(errListenUDP, ErrSetup)
 	}
 
+	if l.capturePath != "" {
+		capture, errCapture := newCaptureWriter(l.capturePath)
+		if errCapture != nil {
+			_ = connection.Close()
+
+			return errors.Join(errCapture, ErrSetup)
+		}
+
+		l.capture = capture
+	}
+
 	l.conn = connection
 	l.udpAddr = udpAddr
 
@@ -82,9 +123,8 @@ func (l *Remote) Open() error {
 // every 60 minutes so that it remains up to date.
 func (l *Remote) Start(ctx context.Context, receiver Receiver) {
 	var (
-		insecureCount       = uint64(0)
-		serverMessageCounts = map[int]int{}
-		logTicker           = time.NewTicker(time.Second * 5)
+		insecureCount = uint64(0)
+		logTicker     = time.NewTicker(time.Second * 5)
 	)
 
 	slog.Info("Starting log reader", slog.String("listen_addr", l.udpAddr.String()+"/udp"))
@@ -93,7 +133,7 @@ func (l *Remote) Start(ctx context.Context, receiver Receiver) {
 		select {
 		case <-logTicker.C:
 			var args []any
-			for logSecret, count := range serverMessageCounts {
+			for logSecret, count := range l.PacketCounts() {
 				args = append(args, slog.String("server_id:count", fmt.Sprintf("%d:%d", logSecret, count)))
 			}
 			slog.Info("Log message counts", args...)
@@ -105,7 +145,7 @@ func (l *Remote) Start(ctx context.Context, receiver Receiver) {
 			}
 			buffer := make([]byte, 1024)
 
-			readLen, _, errReadUDP := l.conn.ReadFromUDP(buffer)
+			readLen, source, errReadUDP := l.conn.ReadFromUDP(buffer)
 			if errReadUDP != nil {
 				if netErr, ok := errReadUDP.(net.Error); ok && netErr.Timeout() {
 					continue
@@ -120,10 +160,20 @@ func (l *Remote) Start(ctx context.Context, receiver Receiver) {
 				continue
 			}
 
-			var reqSecret int
+			if l.capture != nil {
+				if errWrite := l.capture.Write(source.String(), buffer[:readLen]); errWrite != nil {
+					slog.Error("Failed to write log capture", slog.String("error", errWrite.Error()))
+				}
+			}
+
+			secret, line, errPacket := parsePacket(buffer[:readLen])
+			if errPacket != nil {
+				slog.Warn("Received malformed log message", slog.String("error", errPacket.Error()))
 
-			switch srcdsPacket(buffer[4]) {
-			case s2aLogString: // Legacy/insecure format (no secret)
+				continue
+			}
+
+			if secret == 0 {
 				// Only care if we actually set a secret
 				if l.secret > 0 {
 					if insecureCount%100 == 0 {
@@ -133,41 +183,51 @@ func (l *Remote) Start(ctx context.Context, receiver Receiver) {
 					insecureCount++
 				}
 
-				line := strings.TrimSpace(string(buffer))
-				// slog.Debug("Log line", slog.String("src", "debug"), slog.String("line", line))
 				receiver.Send("", line)
-			case s2aLogString2: // Secure format (with secret)
-				line := string(buffer)
-				idx := strings.Index(line, "L ")
-				if idx == -1 {
-					slog.Warn("Received malformed log message: Failed to find marker")
+			} else {
+				hostPort, found := l.ServerHostMap[secret]
+				if !found {
+					slog.Error("Got unknown log secret", slog.Int("log_secret", secret))
 
 					continue
 				}
 
-				secret, errConv := strconv.ParseInt(line[5:idx], 10, 32)
-				if errConv != nil {
-					slog.Error("Received malformed log message: Failed to parse secret",
-						slog.String("error", errConv.Error()))
-
-					continue
-				}
-				linePart := strings.TrimSpace(line[idx:readLen])
-				// slog.Debug("Log line", slog.String("src", "debug"), slog.String("line", linePart))
-				hostPort, found := l.ServerHostMap[int(secret)]
-				if !found {
-					slog.Error("Got unknown log secret", slog.Int64("log_secret", secret))
-					continue
-				}
-				receiver.Send(hostPort, linePart)
-				reqSecret = int(secret)
+				receiver.Send(hostPort, line)
 			}
 
-			if _, ok := serverMessageCounts[reqSecret]; !ok {
-				serverMessageCounts[reqSecret] = 1
-			} else {
-				serverMessageCounts[reqSecret]++
-			}
+			l.countsMu.Lock()
+			l.packetCounts[secret]++
+			l.countsMu.Unlock()
 		}
 	}
 }
+
+// parsePacket returns the log secret and line of a raw srcds log packet. The secret is 0 for the legacy
+// packet type which is sent without one.
+func parsePacket(packet []byte) (int, string, error) {
+	// Too short to contain the header and packet type.
+	if len(packet) < 5 {
+		return 0, "", fmt.Errorf("%w: too short", ErrPacket)
+	}
+
+	switch srcdsPacket(packet[4]) {
+	case s2aLogString: // Legacy/insecure format (no secret)
+		// Skip the header and packet type, the line itself is null terminated.
+		return 0, strings.TrimSpace(strings.TrimRight(string(packet[5:]), "\x00")), nil
+	case s2aLogString2: // Secure format (with secret)
+		line := string(packet)
+		idx := strings.Index(line, "L ")
+		if idx < 5 {
+			return 0, "", fmt.Errorf("%w: failed to find marker", ErrPacket)
+		}
+
+		secret, errConv := strconv.ParseInt(line[5:idx], 10, 32)
+		if errConv != nil {
+			return 0, "", errors.Join(errConv, fmt.Errorf("%w: failed to parse secret", ErrPacket))
+		}
+
+		return int(secret), strings.TrimSpace(strings.TrimRight(line[idx:], "\x00")), nil
+	default:
+		return 0, "", fmt.Errorf("%w: unknown packet type 0x%x", ErrPacket, packet[4])
+	}
+}
@@ -0,0 +1,345 @@
+package console
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+)
+
+// MaxSpeed replays lines as fast as they can be sent, ignoring the delay between them.
+const MaxSpeed = 0
+
+var ErrReplaySpeed = errors.New("invalid replay speed")
+
+// ParseReplaySpeed parses a playback speed multiplier such as "1x", "10" or "max".
+func ParseReplaySpeed(value string) (float64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "max" {
+		return MaxSpeed, nil
+	}
+
+	speed, errSpeed := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	if errSpeed != nil || speed <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrReplaySpeed, value)
+	}
+
+	return speed, nil
+}
+
+// ReplayFile is a saved log file or packet capture, and the server that its lines are attributed to.
+type ReplayFile struct {
+	HostPort string
+	Path     string
+}
+
+// ReplayLine is a single line to be replayed.
+type ReplayLine struct {
+	HostPort string
+	Line     string
+	// Timestamp is when the line was originally logged, or received for packet captures. This is zero for
+	// lines before the first timestamped line.
+	Timestamp time.Time
+}
+
+// ReplayStatus is the current playback state of a Replay.
+type ReplayStatus struct {
+	// Position is the timestamp of the next line to be sent.
+	Position time.Time
+	Start    time.Time
+	End      time.Time
+	Lines    int
+	Sent     int
+	Speed    float64
+	Paused   bool
+	Done     bool
+}
+
+// Replay plays back saved console.log or srcds log files, using the timestamps embedded in each line
+// to reproduce the original timing between lines. Lines from multiple files are merged in time order so
+// that logs from multiple servers can be replayed together.
+//
+// Packet captures written by Remote are also supported. These are decoded the same as live packets, using
+// the arrival time of each packet for timing.
+type Replay struct {
+	files []ReplayFile
+	// serverHostMap maps log secrets to host:port identifiers, the same as Remote.
+	serverHostMap map[int]string
+	mu            *sync.Mutex
+	lines         []ReplayLine
+	position      int
+	speed         float64
+	paused        bool
+	// changed wakes the playback loop when the position, speed or paused state are changed.
+	changed chan struct{}
+}
+
+func NewReplay(speed float64, serverHostMap map[int]string, files ...ReplayFile) *Replay {
+	return &Replay{
+		files:         files,
+		serverHostMap: serverHostMap,
+		mu:            &sync.Mutex{},
+		speed:         speed,
+		changed:       make(chan struct{}, 1),
+	}
+}
+
+// Open reads all the lines of every file into memory.
+func (r *Replay) Open() error {
+	var lines []ReplayLine
+
+	for _, file := range r.files {
+		capture, errCapture := isCapture(file.Path)
+		if errCapture != nil {
+			return errors.Join(errCapture, ErrOpen)
+		}
+
+		read := readReplayFile
+		if capture {
+			read = r.readCaptureFile
+		}
+
+		fileLines, errRead := read(file)
+		if errRead != nil {
+			return errors.Join(errRead, ErrOpen)
+		}
+
+		lines = append(lines, fileLines...)
+	}
+
+	// Stable so that lines sharing the same second remain in their original order.
+	slices.SortStableFunc(lines, func(a, b ReplayLine) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	r.mu.Lock()
+	r.lines = lines
+	r.position = 0
+	r.mu.Unlock()
+
+	return nil
+}
+
+// readReplayFile reads all lines of a log file. Lines without a timestamp, such as status output or those
+// logged before con_timestamp was enabled, use the timestamp of the previous line.
+func readReplayFile(file ReplayFile) ([]ReplayLine, error) {
+	reader, errReader := os.Open(file.Path)
+	if errReader != nil {
+		return nil, errReader
+	}
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	var (
+		lines    []ReplayLine
+		previous time.Time
+		scanner  = bufio.NewScanner(reader)
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if timestamp, found := events.LineTimestamp(line); found {
+			previous = timestamp
+		}
+
+		lines = append(lines, ReplayLine{HostPort: file.HostPort, Line: line, Timestamp: previous})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// readCaptureFile decodes the packets of a capture into lines. Packets sent without a log secret are
+// attributed to the server of the file, while the rest use the server matching their secret.
+func (r *Replay) readCaptureFile(file ReplayFile) ([]ReplayLine, error) {
+	reader, errReader := os.Open(file.Path)
+	if errReader != nil {
+		return nil, errReader
+	}
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	packets, errPackets := ReadCapture(reader)
+	if errPackets != nil {
+		return nil, errPackets
+	}
+
+	lines := make([]ReplayLine, 0, len(packets))
+	for _, packet := range packets {
+		secret, line, errPacket := parsePacket(packet.Data)
+		if errPacket != nil {
+			slog.Warn("Skipping malformed captured packet", slog.String("error", errPacket.Error()))
+
+			continue
+		}
+
+		hostPort := file.HostPort
+		if secret > 0 {
+			var found bool
+			if hostPort, found = r.serverHostMap[secret]; !found {
+				slog.Warn("Skipping captured packet with unknown log secret", slog.Int("log_secret", secret))
+
+				continue
+			}
+		}
+
+		lines = append(lines, ReplayLine{HostPort: hostPort, Line: line, Timestamp: packet.Time})
+	}
+
+	return lines, nil
+}
+
+func (r *Replay) Close(_ context.Context) error {
+	return nil
+}
+
+// Start sends each line to the receiver, waiting between lines according to their timestamps and the
+// current speed. Once all lines are sent, this waits until the context is cancelled so that seeking
+// backwards remains possible.
+func (r *Replay) Start(ctx context.Context, receiver Receiver) {
+	for {
+		line, position, delay, ready := r.next()
+
+		var timer <-chan time.Time
+		if ready {
+			timer = time.After(delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.changed:
+			// The next line or its delay may have changed.
+			continue
+		case <-timer:
+		}
+
+		r.mu.Lock()
+		// Seeking may have raced with the timer.
+		if r.position != position {
+			r.mu.Unlock()
+
+			continue
+		}
+		r.position++
+		r.mu.Unlock()
+
+		slog.Debug("Log line", slog.String("src", "replay"), slog.String("line", line.Line))
+		receiver.Send(line.HostPort, line.Line)
+	}
+}
+
+// next returns the next line to send and how long to wait before sending it. ready is false when paused or
+// when there are no more lines to send.
+func (r *Replay) next() (ReplayLine, int, time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.paused || r.position >= len(r.lines) {
+		return ReplayLine{}, r.position, 0, false
+	}
+
+	line := r.lines[r.position]
+	if r.speed == MaxSpeed || r.position == 0 {
+		return line, r.position, 0, true
+	}
+
+	previous := r.lines[r.position-1]
+	if previous.Timestamp.IsZero() || line.Timestamp.Before(previous.Timestamp) {
+		return line, r.position, 0, true
+	}
+
+	return line, r.position, time.Duration(float64(line.Timestamp.Sub(previous.Timestamp)) / r.speed), true
+}
+
+// notify wakes the playback loop, if it is not already due to be woken.
+func (r *Replay) notify() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// TogglePause pauses or resumes playback, returning true if now paused.
+func (r *Replay) TogglePause() bool {
+	r.mu.Lock()
+	r.paused = !r.paused
+	paused := r.paused
+	r.mu.Unlock()
+
+	r.notify()
+
+	return paused
+}
+
+// SetSpeed changes the playback speed multiplier. MaxSpeed sends lines without any delay.
+func (r *Replay) SetSpeed(speed float64) {
+	r.mu.Lock()
+	r.speed = speed
+	r.mu.Unlock()
+
+	r.notify()
+}
+
+// Seek moves playback forwards or backwards relative to the current position. Seeking backwards replays
+// lines that have already been sent.
+func (r *Replay) Seek(offset time.Duration) {
+	r.mu.Lock()
+	if len(r.lines) == 0 {
+		r.mu.Unlock()
+
+		return
+	}
+
+	current := r.lines[min(r.position, len(r.lines)-1)].Timestamp
+	target := current.Add(offset)
+
+	r.position, _ = slices.BinarySearchFunc(r.lines, target, func(line ReplayLine, target time.Time) int {
+		return line.Timestamp.Compare(target)
+	})
+	r.mu.Unlock()
+
+	r.notify()
+}
+
+// Status returns the current playback state.
+func (r *Replay) Status() ReplayStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := ReplayStatus{
+		Lines:  len(r.lines),
+		Sent:   r.position,
+		Speed:  r.speed,
+		Paused: r.paused,
+		Done:   r.position >= len(r.lines),
+	}
+
+	if len(r.lines) > 0 {
+		status.Start = r.lines[0].Timestamp
+		status.End = r.lines[len(r.lines)-1].Timestamp
+		status.Position = r.lines[min(r.position, len(r.lines)-1)].Timestamp
+	}
+
+	return status
+}
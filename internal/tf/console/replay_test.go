@@ -0,0 +1,131 @@
+package console_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/tf/console"
+	"github.com/stretchr/testify/require"
+)
+
+type testReceiver struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *testReceiver) Send(hostPort string, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines = append(r.lines, hostPort+" "+message)
+}
+
+func (r *testReceiver) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lines
+}
+
+func writeLog(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestReplay(t *testing.T) {
+	first := writeLog(t, "first.log", "header\n"+
+		"L 08/16/2025 - 01:00:00: a\n"+
+		"L 08/16/2025 - 01:00:02: c\n")
+	second := writeLog(t, "second.log", "08/16/2025 - 01:00:01: b\n"+
+		"status output\n"+
+		"08/16/2025 - 01:10:00: d\n")
+
+	replay := console.NewReplay(console.MaxSpeed, nil,
+		console.ReplayFile{HostPort: "1.1.1.1:27015", Path: first},
+		console.ReplayFile{HostPort: "2.2.2.2:27015", Path: second})
+	require.NoError(t, replay.Open())
+	require.True(t, replay.TogglePause())
+
+	receiver := &testReceiver{}
+	go replay.Start(t.Context(), receiver)
+
+	status := replay.Status()
+	require.Equal(t, 6, status.Lines)
+	require.Equal(t, time.Date(2025, time.August, 16, 1, 10, 0, 0, time.Local), status.End)
+
+	require.False(t, replay.TogglePause())
+	require.Eventually(t, func() bool { return replay.Status().Done }, time.Second, time.Millisecond*10)
+
+	require.Equal(t, []string{
+		"1.1.1.1:27015 header",
+		"1.1.1.1:27015 L 08/16/2025 - 01:00:00: a",
+		"2.2.2.2:27015 08/16/2025 - 01:00:01: b",
+		"2.2.2.2:27015 status output",
+		"1.1.1.1:27015 L 08/16/2025 - 01:00:02: c",
+		"2.2.2.2:27015 08/16/2025 - 01:10:00: d",
+	}, receiver.Lines())
+
+	// Seeking backwards replays everything from the target time.
+	replay.Seek(-(time.Minute*10 - time.Second*2))
+	require.Eventually(t, func() bool { return len(receiver.Lines()) == 8 }, time.Second, time.Millisecond*10)
+	require.Equal(t, "1.1.1.1:27015 L 08/16/2025 - 01:00:02: c", receiver.Lines()[6])
+}
+
+func TestParseReplaySpeed(t *testing.T) {
+	for value, expected := range map[string]float64{"1x": 1, "10": 10, "0.5x": 0.5, "MAX": console.MaxSpeed} {
+		speed, err := console.ParseReplaySpeed(value)
+		require.NoError(t, err)
+		require.InDelta(t, expected, speed, 0.0001)
+	}
+
+	for _, value := range []string{"", "0x", "-1", "fast"} {
+		_, err := console.ParseReplaySpeed(value)
+		require.ErrorIs(t, err, console.ErrReplaySpeed)
+	}
+}
+
+func TestReplayCapture(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		encoder = json.NewEncoder(&buf)
+		start   = time.Date(2025, time.August, 16, 1, 0, 0, 0, time.UTC)
+	)
+
+	for idx, data := range []string{
+		"\xff\xff\xff\xffRL 08/16/2025 - 01:00:00: insecure\x00",
+		"\xff\xff\xff\xffS1234L 08/16/2025 - 01:00:00: secure\x00",
+		"\xff\xff\xff\xffS999L 08/16/2025 - 01:00:00: unknown secret\x00",
+		"\xff\xff",
+	} {
+		require.NoError(t, encoder.Encode(console.CapturedPacket{
+			Time: start.Add(time.Duration(idx) * time.Second), Source: "3.3.3.3:27015", Data: []byte(data),
+		}))
+	}
+
+	packets, errRead := console.ReadCapture(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, errRead)
+	require.Len(t, packets, 4)
+	require.Equal(t, "3.3.3.3:27015", packets[0].Source)
+
+	replay := console.NewReplay(console.MaxSpeed, map[int]string{1234: "2.2.2.2:27015"},
+		console.ReplayFile{HostPort: "1.1.1.1:27015", Path: writeLog(t, "capture.jsonl", buf.String())})
+	require.NoError(t, replay.Open())
+
+	receiver := &testReceiver{}
+	go replay.Start(t.Context(), receiver)
+
+	require.Eventually(t, func() bool { return replay.Status().Done }, time.Second, time.Millisecond*10)
+	require.Equal(t, []string{
+		"1.1.1.1:27015 L 08/16/2025 - 01:00:00: insecure",
+		"2.2.2.2:27015 L 08/16/2025 - 01:00:00: secure",
+	}, receiver.Lines())
+}
@@ -0,0 +1,315 @@
+package events
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+)
+
+// LogPlayer is a player as identified in srcds log lines, eg: "Name<2><[U:1:1]><Red>".
+type LogPlayer struct {
+	Player    string
+	UserID    int
+	PlayerSID steamid.SteamID
+	Team      tf.Team
+}
+
+// Position is a position on the map.
+type Position struct {
+	X int
+	Y int
+	Z int
+}
+
+// JoinedTeamEvent is sent in server mode when a player changes team.
+type JoinedTeamEvent struct {
+	LogPlayer
+	// NewTeam is the team joined, LogPlayer.Team is the team the player is leaving.
+	NewTeam tf.Team
+}
+
+// ChangedRoleEvent is sent in server mode when a player changes class.
+type ChangedRoleEvent struct {
+	LogPlayer
+	Class tf.PlayerClass
+}
+
+// EnteredEvent is sent in server mode once a connecting player has finished loading in.
+type EnteredEvent struct {
+	LogPlayer
+}
+
+// PlayerDisconnectEvent is sent in server mode when a player leaves the server. This is distinct from
+// DisconnectEvent which is sent when we leave a server in client mode.
+type PlayerDisconnectEvent struct {
+	LogPlayer
+	Reason string
+}
+
+type RoundStartEvent struct{}
+
+type RoundWinEvent struct {
+	Winner tf.Team
+}
+
+type PointCapturedEvent struct {
+	Team    tf.Team
+	CP      int
+	CPName  string
+	Cappers []LogPlayer
+}
+
+type ChargeType string
+
+const (
+	ChargeDeployed ChargeType = "chargedeployed"
+	ChargeReady    ChargeType = "chargeready"
+	ChargeEnded    ChargeType = "chargeended"
+)
+
+type MedicChargeEvent struct {
+	LogPlayer
+	Charge  ChargeType
+	Medigun string
+	// Duration is only set for ChargeEnded.
+	Duration time.Duration
+}
+
+type MedicDeathEvent struct {
+	Killer LogPlayer
+	Medic  LogPlayer
+	// Healing is the amount of healing done by the medic during this life.
+	Healing int
+	// Ubercharge is true when the medic died with a full charge.
+	Ubercharge bool
+}
+
+const (
+	// logPlayerIdentPattern matches a player identifier. Bots and the console use BOT and Console in place
+	// of a steam id.
+	logPlayerIdentPattern = `(.+?)<(\d+)><(\[U:\d:\d+]|BOT|Console)><(\w*)>`
+	// logPlayerPattern matches the quoted player identifiers within srcds log lines.
+	logPlayerPattern = `"` + logPlayerIdentPattern + `"`
+)
+
+var (
+	logPlayerRx      = regexp.MustCompile(logPlayerPattern)
+	logPlayerIdentRx = regexp.MustCompile(`^` + logPlayerIdentPattern + `$`)
+	// logLineRx matches the prefix of every srcds log line, eg: L 08/16/2025 - 01:25:53: .
+	logLineRx = regexp.MustCompile(`^L (\d{2}/\d{2}/\d{4} - \d{2}:\d{2}:\d{2}): (.+)$`)
+	// propertiesRx matches the trailing key/value properties of a log line, eg: (customkill "headshot").
+	propertiesRx = regexp.MustCompile(`\((\w+) "([^"]*)"\)`)
+)
+
+// ParseLogPlayers returns all the players identified in a srcds log line.
+func ParseLogPlayers(line string) []LogPlayer {
+	var players []LogPlayer
+	for _, match := range logPlayerRx.FindAllStringSubmatch(line, -1) {
+		players = append(players, newLogPlayer(match[1:5]))
+	}
+
+	return players
+}
+
+// newLogPlayer creates a player from the name, user id, steam id and team match groups.
+func newLogPlayer(match []string) LogPlayer {
+	userID, _ := strconv.Atoi(match[1])
+
+	player := LogPlayer{Player: match[0], UserID: userID, Team: tf.ParseLogTeam(match[3])}
+	if sid := steamid.New(match[2]); sid.Valid() {
+		player.PlayerSID = sid
+	}
+
+	return player
+}
+
+// IsLogLine returns true if the line is in the srcds log format, rather than the client console format.
+func IsLogLine(line string) bool {
+	return logLineRx.MatchString(line)
+}
+
+func parseProperties(line string) map[string]string {
+	properties := map[string]string{}
+	for _, match := range propertiesRx.FindAllStringSubmatch(line, -1) {
+		properties[match[1]] = match[2]
+	}
+
+	return properties
+}
+
+// parseCappers returns the players listed in the numbered player properties of a point capture.
+func parseCappers(properties map[string]string) []LogPlayer {
+	numCappers, _ := strconv.Atoi(properties["numcappers"])
+
+	var cappers []LogPlayer
+	for idx := 1; idx <= numCappers; idx++ {
+		match := logPlayerIdentRx.FindStringSubmatch(properties["player"+strconv.Itoa(idx)])
+		if match == nil {
+			continue
+		}
+
+		cappers = append(cappers, newLogPlayer(match[1:5]))
+	}
+
+	return cappers
+}
+
+func parsePosition(value string) Position {
+	var position Position
+
+	parts := strings.Fields(value)
+	if len(parts) != 3 {
+		return position
+	}
+
+	position.X, _ = strconv.Atoi(parts[0])
+	position.Y, _ = strconv.Atoi(parts[1])
+	position.Z, _ = strconv.Atoi(parts[2])
+
+	return position
+}
+
+// LogParser parses the log lines sent by srcds over udp in server mode. Unlike the client console, these
+// identify players by their steam id and team, eg:
+//
+//	L 08/16/2025 - 01:25:53: "Name<2><[U:1:1]><Red>" say "hello"
+type LogParser struct {
+	rx []regexPair
+}
+
+func NewLogParser() *LogParser {
+	return &LogParser{
+		rx: []regexPair{
+			{eventType: Kill, regex: regexp.MustCompile(`^` + logPlayerPattern + ` killed ` + logPlayerPattern + ` with "([^"]*)"(.*)$`)},
+			{eventType: Msg, regex: regexp.MustCompile(`^` + logPlayerPattern + ` (say|say_team) "(.*)"$`)},
+			{eventType: Connect, regex: regexp.MustCompile(`^` + logPlayerPattern + ` connected, address "([^"]*)"$`)},
+			{eventType: Entered, regex: regexp.MustCompile(`^` + logPlayerPattern + ` entered the game$`)},
+			{eventType: PlayerDisconnect, regex: regexp.MustCompile(`^` + logPlayerPattern + ` disconnected \(reason "(.*)"\)$`)},
+			{eventType: JoinedTeam, regex: regexp.MustCompile(`^` + logPlayerPattern + ` joined team "(\w+)"$`)},
+			{eventType: ChangedRole, regex: regexp.MustCompile(`^` + logPlayerPattern + ` changed role to "(\w+)"$`)},
+			{eventType: MedicDeath, regex: regexp.MustCompile(`^` + logPlayerPattern + ` triggered "medic_death" against ` + logPlayerPattern + `(.*)$`)},
+			{eventType: MedicCharge, regex: regexp.MustCompile(`^` + logPlayerPattern + ` triggered "(chargedeployed|chargeready|chargeended)"(.*)$`)},
+			{eventType: RoundStart, regex: regexp.MustCompile(`^World triggered "Round_Start"`)},
+			{eventType: RoundWin, regex: regexp.MustCompile(`^World triggered "Round_Win"(.*)$`)},
+			{eventType: PointCaptured, regex: regexp.MustCompile(`^Team "(\w+)" triggered "pointcaptured"(.*)$`)},
+			{eventType: Map, regex: regexp.MustCompile(`^(?:Loading|Started) map "([^"]+)"`)},
+		},
+	}
+}
+
+// Parse parses a single srcds log line. Lines without the srcds prefix, or not matching any known
+// event return ErrNoMatch.
+func (parser *LogParser) Parse(line string) (Event, error) {
+	prefix := logLineRx.FindStringSubmatch(line)
+	if prefix == nil {
+		return Event{}, ErrNoMatch
+	}
+
+	timestamp, errTimestamp := parseTimestamp(prefix[1])
+	if errTimestamp != nil {
+		timestamp = time.Now()
+	}
+
+	body := prefix[2]
+
+	for _, rxMatcher := range parser.rx {
+		match := rxMatcher.regex.FindStringSubmatch(body)
+		if match == nil {
+			continue
+		}
+
+		outEvent := Event{Type: rxMatcher.eventType, Raw: line, Timestamp: timestamp}
+		outEvent.Data = parseLogData(rxMatcher.eventType, match)
+
+		return outEvent, nil
+	}
+
+	return Event{}, ErrNoMatch
+}
+
+func parseLogData(eventType EventType, match []string) any { //nolint:cyclop
+	switch eventType { //nolint:exhaustive
+	case Kill:
+		player, victim := newLogPlayer(match[1:5]), newLogPlayer(match[5:9])
+		properties := parseProperties(match[10])
+
+		return KillEvent{
+			Player:         player.Player,
+			PlayerSID:      player.PlayerSID,
+			PlayerTeam:     player.Team,
+			Victim:         victim.Player,
+			VictimSID:      victim.PlayerSID,
+			VictimTeam:     victim.Team,
+			Weapon:         match[9],
+			CustomKill:     properties["customkill"],
+			PlayerPosition: parsePosition(properties["attacker_position"]),
+			VictimPosition: parsePosition(properties["victim_position"]),
+		}
+	case Msg:
+		player := newLogPlayer(match[1:5])
+
+		return MsgEvent{
+			Player:    player.Player,
+			PlayerSID: player.PlayerSID,
+			TeamOnly:  match[5] == "say_team",
+			Message:   match[6],
+		}
+	case Connect:
+		player := newLogPlayer(match[1:5])
+
+		return ConnectEvent{Player: player.Player, PlayerSID: player.PlayerSID, UserID: player.UserID, Address: match[5]}
+	case Entered:
+		return EnteredEvent{LogPlayer: newLogPlayer(match[1:5])}
+	case PlayerDisconnect:
+		return PlayerDisconnectEvent{LogPlayer: newLogPlayer(match[1:5]), Reason: match[5]}
+	case JoinedTeam:
+		return JoinedTeamEvent{LogPlayer: newLogPlayer(match[1:5]), NewTeam: tf.ParseLogTeam(match[5])}
+	case ChangedRole:
+		return ChangedRoleEvent{LogPlayer: newLogPlayer(match[1:5]), Class: tf.ParseLogClass(match[5])}
+	case MedicDeath:
+		properties := parseProperties(match[9])
+		healing, _ := strconv.Atoi(properties["healing"])
+
+		return MedicDeathEvent{
+			Killer:     newLogPlayer(match[1:5]),
+			Medic:      newLogPlayer(match[5:9]),
+			Healing:    healing,
+			Ubercharge: properties["ubercharge"] == "1",
+		}
+	case MedicCharge:
+		properties := parseProperties(match[6])
+		event := MedicChargeEvent{
+			LogPlayer: newLogPlayer(match[1:5]),
+			Charge:    ChargeType(match[5]),
+			Medigun:   properties["medigun"],
+		}
+
+		if duration, errDuration := strconv.ParseFloat(properties["duration"], 64); errDuration == nil {
+			event.Duration = time.Duration(duration * float64(time.Second))
+		}
+
+		return event
+	case RoundStart:
+		return RoundStartEvent{}
+	case RoundWin:
+		return RoundWinEvent{Winner: tf.ParseLogTeam(parseProperties(match[1])["winner"])}
+	case PointCaptured:
+		properties := parseProperties(match[2])
+		capturePoint, _ := strconv.Atoi(properties["cp"])
+
+		return PointCapturedEvent{
+			Team:    tf.ParseLogTeam(match[1]),
+			CP:      capturePoint,
+			CPName:  properties["cpname"],
+			Cappers: parseCappers(properties),
+		}
+	case Map:
+		return MapEvent{MapName: match[1]}
+	default:
+		return AnyEvent{Raw: match[0]}
+	}
+}
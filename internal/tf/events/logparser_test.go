@@ -0,0 +1,139 @@
+package events_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogParser(t *testing.T) {
+	type tc struct {
+		Line   string
+		Result events.Event
+	}
+
+	var (
+		playerOne = events.LogPlayer{Player: "Player One", UserID: 2, PlayerSID: steamid.New("[U:1:1]"), Team: tf.RED}
+		playerTwo = events.LogPlayer{Player: "Player Two", UserID: 3, PlayerSID: steamid.New("[U:1:2]"), Team: tf.BLU}
+	)
+
+	cases := []tc{
+		{
+			Line: `L 08/16/2025 - 01:26:01: "Player One<2><[U:1:1]><Red>" killed "Player Two<3><[U:1:2]><Blue>" with "sniperrifle" ` +
+				`(customkill "headshot") (attacker_position "-1201 330 -95") (victim_position "-512 1024 -63")`,
+			Result: events.Event{Type: events.Kill, Data: events.KillEvent{
+				Player: "Player One", PlayerSID: playerOne.PlayerSID, PlayerTeam: tf.RED,
+				Victim: "Player Two", VictimSID: playerTwo.PlayerSID, VictimTeam: tf.BLU,
+				Weapon: "sniperrifle", CustomKill: "headshot",
+				PlayerPosition: events.Position{X: -1201, Y: 330, Z: -95},
+				VictimPosition: events.Position{X: -512, Y: 1024, Z: -63},
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:26:02: "Player One<2><[U:1:1]><Red>" say "gg"`,
+			Result: events.Event{Type: events.Msg, Data: events.MsgEvent{
+				Player: "Player One", PlayerSID: playerOne.PlayerSID, Message: "gg",
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:26:03: "Player Two<3><[U:1:2]><Blue>" say_team "push "now""`,
+			Result: events.Event{Type: events.Msg, Data: events.MsgEvent{
+				Player: "Player Two", PlayerSID: playerTwo.PlayerSID, Message: `push "now"`, TeamOnly: true,
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:25:50: "Player One<2><[U:1:1]><>" connected, address "1.2.3.4:27005"`,
+			Result: events.Event{Type: events.Connect, Data: events.ConnectEvent{
+				Player: "Player One", PlayerSID: playerOne.PlayerSID, UserID: 2, Address: "1.2.3.4:27005",
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:25:52: "Player One<2><[U:1:1]><>" entered the game`,
+			Result: events.Event{Type: events.Entered, Data: events.EnteredEvent{
+				LogPlayer: events.LogPlayer{Player: "Player One", UserID: 2, PlayerSID: playerOne.PlayerSID},
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:40:00: "Player Two<3><[U:1:2]><Blue>" disconnected (reason "Disconnect by user.")`,
+			Result: events.Event{Type: events.PlayerDisconnect, Data: events.PlayerDisconnectEvent{
+				LogPlayer: playerTwo, Reason: "Disconnect by user.",
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:25:53: "Player One<2><[U:1:1]><Unassigned>" joined team "Red"`,
+			Result: events.Event{Type: events.JoinedTeam, Data: events.JoinedTeamEvent{
+				LogPlayer: events.LogPlayer{Player: "Player One", UserID: 2, PlayerSID: playerOne.PlayerSID, Team: tf.UNASSIGNED},
+				NewTeam:   tf.RED,
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:25:54: "Player One<2><[U:1:1]><Red>" changed role to "heavyweapons"`,
+			Result: events.Event{Type: events.ChangedRole, Data: events.ChangedRoleEvent{
+				LogPlayer: playerOne,
+				Class:     tf.Heavy,
+			}},
+		}, {
+			Line:   `L 08/16/2025 - 01:26:00: World triggered "Round_Start"`,
+			Result: events.Event{Type: events.RoundStart, Data: events.RoundStartEvent{}},
+		}, {
+			Line:   `L 08/16/2025 - 01:35:00: World triggered "Round_Win" (winner "Blue")`,
+			Result: events.Event{Type: events.RoundWin, Data: events.RoundWinEvent{Winner: tf.BLU}},
+		}, {
+			Line: `L 08/16/2025 - 01:30:00: Team "Blue" triggered "pointcaptured" (cp "1") (cpname "#koth_viaduct_cap") ` +
+				`(numcappers "1") (player1 "Player Two<3><[U:1:2]><Blue>") (position1 "10 20 30")`,
+			Result: events.Event{Type: events.PointCaptured, Data: events.PointCapturedEvent{
+				Team: tf.BLU, CP: 1, CPName: "#koth_viaduct_cap", Cappers: []events.LogPlayer{playerTwo},
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:31:00: "Player One<2><[U:1:1]><Red>" triggered "chargedeployed" (medigun "medigun")`,
+			Result: events.Event{Type: events.MedicCharge, Data: events.MedicChargeEvent{
+				LogPlayer: playerOne, Charge: events.ChargeDeployed, Medigun: "medigun",
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:31:08: "Player One<2><[U:1:1]><Red>" triggered "chargeended" (duration "7.5")`,
+			Result: events.Event{Type: events.MedicCharge, Data: events.MedicChargeEvent{
+				LogPlayer: playerOne, Charge: events.ChargeEnded, Duration: time.Millisecond * 7500,
+			}},
+		}, {
+			Line: `L 08/16/2025 - 01:32:00: "Player Two<3><[U:1:2]><Blue>" triggered "medic_death" against ` +
+				`"Player One<2><[U:1:1]><Red>" (healing "1250") (ubercharge "1")`,
+			Result: events.Event{Type: events.MedicDeath, Data: events.MedicDeathEvent{
+				Killer: playerTwo, Medic: playerOne, Healing: 1250, Ubercharge: true,
+			}},
+		}, {
+			Line:   `L 08/16/2025 - 01:20:00: Loading map "koth_viaduct"`,
+			Result: events.Event{Type: events.Map, Data: events.MapEvent{MapName: "koth_viaduct"}},
+		}, {
+			Line:   `L 08/16/2025 - 01:20:01: Started map "koth_viaduct" (CRC "abc123")`,
+			Result: events.Event{Type: events.Map, Data: events.MapEvent{MapName: "koth_viaduct"}},
+		},
+	}
+
+	parser := events.NewLogParser()
+
+	for index, testCase := range cases {
+		evt, err := parser.Parse(testCase.Line)
+		require.NoError(t, err, fmt.Sprintf("Test %d fail - parse", index))
+		require.Equal(t, testCase.Result.Type, evt.Type, fmt.Sprintf("Test %d fail - type", index))
+		require.Equal(t, testCase.Result.Data, evt.Data, fmt.Sprintf("Test %d fail - data", index))
+		require.Equal(t, testCase.Line, evt.Raw)
+	}
+
+	for _, line := range []string{
+		`L 08/16/2025 - 01:20:00: server_cvar: "sv_cheats" "0"`,
+		`Player One killed Player Two with scattergun.`,
+	} {
+		_, err := parser.Parse(line)
+		require.ErrorIs(t, err, events.ErrNoMatch)
+	}
+}
+
+func TestParseLogPlayers(t *testing.T) {
+	players := events.ParseLogPlayers(`L 08/16/2025 - 01:26:01: "Player <One><2><[U:1:1]><Red>" killed ` +
+		`"Bot<3><BOT><Blue>" with "minigun" (attacker_position "1 2 3") (victim_position "4 5 6")`)
+
+	require.Equal(t, []events.LogPlayer{
+		{Player: "Player <One>", UserID: 2, PlayerSID: steamid.New("[U:1:1]"), Team: tf.RED},
+		{Player: "Bot", UserID: 3, Team: tf.BLU},
+	}, players)
+
+	require.Empty(t, events.ParseLogPlayers(`L 08/16/2025 - 01:26:01: World triggered "Round_Start"`))
+}
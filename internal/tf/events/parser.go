@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
 )
 
 const (
@@ -27,6 +28,10 @@ var (
 	ErrDuration       = errors.New("failed to parse connected duration")
 )
 
+// timestampRx matches the timestamp prefixing console lines when con_timestamp is enabled, along with the
+// "L " prefix used by srcds log lines, eg: "08/16/2025 - 01:25:53: " or "L 08/16/2025 - 01:25:53: ".
+var timestampRx = regexp.MustCompile(`^(?:L )?(\d{2}/\d{2}/\d{4} - \d{2}:\d{2}:\d{2}):\s+(.*)$`)
+
 type EventType int
 
 const (
@@ -42,8 +47,69 @@ const (
 	Address
 	Stats
 	Version
+	JoinedTeam
+	ChangedRole
+	Entered
+	PlayerDisconnect
+	RoundStart
+	RoundWin
+	PointCaptured
+	MedicCharge
+	MedicDeath
+	Lobby
 )
 
+func (t EventType) String() string {
+	switch t {
+	case Any:
+		return "any"
+	case Kill:
+		return "kill"
+	case Msg:
+		return "msg"
+	case Connect:
+		return "connect"
+	case Disconnect:
+		return "disconnect"
+	case StatusID:
+		return "status_id"
+	case Hostname:
+		return "hostname"
+	case Map:
+		return "map"
+	case Tags:
+		return "tags"
+	case Address:
+		return "address"
+	case Stats:
+		return "stats"
+	case Version:
+		return "version"
+	case JoinedTeam:
+		return "joined_team"
+	case ChangedRole:
+		return "changed_role"
+	case Entered:
+		return "entered"
+	case PlayerDisconnect:
+		return "player_disconnect"
+	case RoundStart:
+		return "round_start"
+	case RoundWin:
+		return "round_win"
+	case PointCaptured:
+		return "point_captured"
+	case MedicCharge:
+		return "medic_charge"
+	case MedicDeath:
+		return "medic_death"
+	case Lobby:
+		return "lobby"
+	default:
+		return "unknown"
+	}
+}
+
 type Event struct {
 	// How we identify the owner of this event.
 	HostPort  string
@@ -70,6 +136,10 @@ type AnyEvent struct {
 
 type ConnectEvent struct {
 	Player string
+	// PlayerSID, UserID and Address are only available in server mode.
+	PlayerSID steamid.SteamID
+	UserID    int
+	Address   string
 }
 
 type DisconnectEvent struct {
@@ -134,6 +204,12 @@ type KillEvent struct {
 	VictimSID steamid.SteamID
 	Weapon    string
 	Crit      bool
+	// The remaining fields are only available in server mode.
+	PlayerTeam     tf.Team
+	VictimTeam     tf.Team
+	CustomKill     string
+	PlayerPosition Position
+	VictimPosition Position
 }
 
 type Parser struct {
@@ -143,9 +219,10 @@ type Parser struct {
 	logger      *slog.Logger
 }
 
-// parseTimestamp will convert the source formatted log timestamps into a time.Time value.
+// parseTimestamp will convert the source formatted log timestamps into a time.Time value. The game does
+// not include a timezone, so the timestamps are assumed to be in local time.
 func parseTimestamp(timestamp string) (time.Time, error) {
-	parsedTime, errParse := time.Parse(logTimestampFormat, timestamp)
+	parsedTime, errParse := time.ParseInLocation(logTimestampFormat, timestamp, time.Local)
 	if errParse != nil {
 		return time.Time{}, errors.Join(errParse, ErrParseTimestamp)
 	}
@@ -153,6 +230,37 @@ func parseTimestamp(timestamp string) (time.Time, error) {
 	return parsedTime, nil
 }
 
+// LineTimestamp returns the timestamp prefixing a console or srcds log line, if any.
+func LineTimestamp(line string) (time.Time, bool) {
+	match := timestampRx.FindStringSubmatch(line)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	timestamp, errTimestamp := parseTimestamp(match[1])
+	if errTimestamp != nil {
+		return time.Time{}, false
+	}
+
+	return timestamp, true
+}
+
+// splitTimestamp separates the leading timestamp from the rest of the line. Lines without a valid
+// timestamp, such as those from a client without con_timestamp enabled, use the current time instead.
+func splitTimestamp(line string) (time.Time, string) {
+	match := timestampRx.FindStringSubmatch(line)
+	if match == nil {
+		return time.Now(), line
+	}
+
+	timestamp, errTimestamp := parseTimestamp(match[1])
+	if errTimestamp != nil {
+		return time.Now(), match[2]
+	}
+
+	return timestamp, match[2]
+}
+
 type regexPair struct {
 	regex     *regexp.Regexp
 	eventType EventType
@@ -162,8 +270,8 @@ func NewParser() *Parser {
 	return &Parser{
 		rx: []regexPair{
 			// 08/16/2025 - 01:25:53: Completed demo, recording time 369.4, game frames 23494.?
-			{eventType: Kill, regex: regexp.MustCompile(`^(?:[01]\d/[0123]\d/20\d{2}\s-\s\d{2}:\d{2}:\d{2}:\s+)?(.+?)\skilled\s(.+?)\swith\s(.+)(\.|\. \(crit\))$`)},
-			{eventType: Msg, regex: regexp.MustCompile(`(?P<name>.+?)\s:\s{2}(?P<message>.+?)$`)},
+			{eventType: Kill, regex: regexp.MustCompile(`^(.+?)\skilled\s(.+?)\swith\s(.+)(\.|\. \(crit\))$`)},
+			{eventType: Msg, regex: regexp.MustCompile(`^(?P<name>.+?)\s:\s{2}(?P<message>.+?)$`)},
 			{eventType: Connect, regex: regexp.MustCompile(`(.+?)\sconnected$`)},
 			{eventType: Disconnect, regex: regexp.MustCompile(`(Connecting to|Differing lobby received.).+?$`)},
 			{eventType: StatusID, regex: regexp.MustCompile(`#\s+(?P<id>\d{1,6})\s"(?P<name>.+?)"\s+(?P<sid>\[U:\d:\d{1,10}])\s{1,8}(?P<time>\d{1,3}:\d{2}(?::\d{2})?)\s+(?P<ping>\d{1,4})\s{1,8}(?P<loss>\d{1,3})\s(spawning|active)(?P<ip>\s+.+?)?$`)},
@@ -172,30 +280,38 @@ func NewParser() *Parser {
 			{eventType: Tags, regex: regexp.MustCompile(`tags\s{4}:\s(.+?)$`)},
 			{eventType: Address, regex: regexp.MustCompile(`udp/ip.+?public IP from Steam: (\d+\.\d+\.\d+\.\d+)`)},
 			{eventType: Version, regex: regexp.MustCompile(`version\s+:.+?\d+\d+\s+(\d+)\s+(secure)?`)},
+			// CTFLobbyShared: ID:00021f0d1d2a6d41  24 member(s), 0 pending
+			{eventType: Lobby, regex: regexp.MustCompile(`^CTFLobbyShared: ID:([0-9a-fA-F]+)`)},
 		},
 	}
 }
 
+// Parse parses a single client console line. The con_timestamp prefix is removed before matching and
+// is used as the event timestamp, while Raw retains the full line.
 func (parser *Parser) Parse(msg string) (Event, error) {
 	// the index must match the index of the EventType const values
 	var outEvent Event
 
+	timestamp, body := splitTimestamp(msg)
+
 	for _, rxMatcher := range parser.rx {
-		match := rxMatcher.regex.FindStringSubmatch(msg)
+		match := rxMatcher.regex.FindStringSubmatch(body)
 		if match == nil {
 			continue
 		}
 		outEvent.Raw = msg
 		outEvent.Type = rxMatcher.eventType
-		outEvent.Timestamp = time.Now()
+		outEvent.Timestamp = timestamp
 
 		switch outEvent.Type { //nolint:exhaustive
 		case Connect:
 			outEvent.Data = ConnectEvent{Player: match[1]}
 		case Disconnect:
+			disconnect := DisconnectEvent{}
 			if len(match) > 3 {
-				outEvent.Data = DisconnectEvent{Player: match[3]}
+				disconnect.Player = match[3]
 			}
+			outEvent.Data = disconnect
 		case Msg:
 			outEvent.Data = parseMsg(match)
 		case StatusID:
@@ -265,8 +381,10 @@ func (parser *Parser) Parse(msg string) (Event, error) {
 				continue
 			}
 			outEvent.Data = AddressEvent{Address: addr}
+		case Lobby:
+			outEvent.Data = LobbyEvent{LobbyID: match[1]}
 		case Any:
-			outEvent.Data = AnyEvent{Raw: msg}
+			outEvent.Data = AnyEvent{Raw: body}
 		}
 
 		return outEvent, nil
@@ -300,8 +418,10 @@ func parseConnected(d string) (time.Duration, error) {
 	return dur, nil
 }
 
+// parseMsg parses a chat line. The console does not include the steam id of the author, so the PlayerSID
+// is left unset for consumers to resolve by name against the current player list.
 func parseMsg(match []string) MsgEvent {
-	name := match[2]
+	name := match[1]
 	dead := false
 	team := false
 
@@ -323,7 +443,6 @@ func parseMsg(match []string) MsgEvent {
 		Player:   name,
 		Dead:     dead,
 		TeamOnly: team,
-		// PlayerSID: steamid.SteamID,
-		Message: match[2],
+		Message:  match[2],
 	}
 }
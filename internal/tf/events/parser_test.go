@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/netip"
 	"testing"
+	"time"
 
 	"github.com/leighmacdonald/steamid/v4/steamid"
 	"github.com/leighmacdonald/tf-tui/internal/tf/events"
@@ -63,6 +64,9 @@ edicts  : 1678 used of 2048 max
 		}, {
 			Line:   "udp/ip  : ?.?.?.?:?  (public IP from Steam: 108.181.62.21)",
 			Result: events.Event{Type: events.Address, Data: events.AddressEvent{Address: netip.MustParseAddr("108.181.62.21")}},
+		}, {
+			Line:   "CTFLobbyShared: ID:00021f0d1d2a6d41  24 member(s), 0 pending",
+			Result: events.Event{Type: events.Lobby, Data: events.LobbyEvent{LobbyID: "00021f0d1d2a6d41"}},
 		}, {
 			Line:   "08/16/2025 - 01:13:50: Umevol killed (TPT) Mystic Ghost with scattergun.",
 			Result: events.Event{Type: events.Kill, Data: events.KillEvent{Player: "Umevol", Victim: "(TPT) Mystic Ghost", Weapon: "scattergun"}},
@@ -75,6 +79,15 @@ edicts  : 1678 used of 2048 max
 		}, {
 			Line:   "GlorpiusJinglebuck killed jaydendillonk with knife. (crit)",
 			Result: events.Event{Type: events.Kill, Data: events.KillEvent{Player: "GlorpiusJinglebuck", Victim: "jaydendillonk", Weapon: "knife", Crit: true}},
+		}, {
+			Line:   "08/16/2025 - 01:17:13: nfd :  gg",
+			Result: events.Event{Type: events.Msg, Data: events.MsgEvent{Player: "nfd", Message: "gg"}},
+		}, {
+			Line:   "08/16/2025 - 01:15:11: *DEAD* Cosmic_Echo :  lol",
+			Result: events.Event{Type: events.Msg, Data: events.MsgEvent{Player: "Cosmic_Echo", Message: "lol", Dead: true}},
+		}, {
+			Line:   "*DEAD*(TEAM) Microwave :  bluetooth fucked",
+			Result: events.Event{Type: events.Msg, Data: events.MsgEvent{Player: "Microwave", Message: "bluetooth fucked", Dead: true, TeamOnly: true}},
 		},
 	}
 
@@ -87,3 +100,23 @@ edicts  : 1678 used of 2048 max
 		require.Equal(t, testCase.Result.Data, evt.Data)
 	}
 }
+
+func TestParserTimestamp(t *testing.T) {
+	expected := time.Date(2025, time.August, 16, 1, 17, 13, 0, time.Local)
+	parser := events.NewParser()
+
+	evt, err := parser.Parse("08/16/2025 - 01:17:13: nfd :  gg")
+	require.NoError(t, err)
+	require.Equal(t, expected, evt.Timestamp)
+	require.Equal(t, "08/16/2025 - 01:17:13: nfd :  gg", evt.Raw)
+
+	logEvt, errLog := events.NewLogParser().Parse(`L 08/16/2025 - 01:17:13: "nfd<2><[U:1:1]><Red>" say "gg"`)
+	require.NoError(t, errLog)
+	require.Equal(t, expected, logEvt.Timestamp)
+
+	// Lines without con_timestamp enabled fall back to the time they were received.
+	before := time.Now()
+	evt, err = parser.Parse("nfd :  gg")
+	require.NoError(t, err)
+	require.False(t, evt.Timestamp.Before(before))
+}
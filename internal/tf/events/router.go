@@ -2,106 +2,326 @@ package events
 
 import (
 	"errors"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/leighmacdonald/tf-tui/internal/config"
 )
 
+// defaultSubscriptionBuffer is the size of the router side queue for DeliverBuffered subscriptions that do
+// not set one.
+const defaultSubscriptionBuffer = 100
+
+// DeliveryPolicy controls what happens to events when a subscriber is not ready to receive them.
+type DeliveryPolicy int
+
+const (
+	// DeliverDrop drops events when the subscriber channel is full. This is the default as a slow subscriber
+	// can never hold up the others.
+	DeliverDrop DeliveryPolicy = iota
+	// DeliverBlock waits for the subscriber to receive each event, holding up delivery to all subscribers
+	// until it does. This should only be used by subscribers that must not miss events and are always receiving.
+	DeliverBlock
+	// DeliverBuffered queues events within the router for the subscriber, dropping them once the queue is full.
+	// Unlike DeliverDrop, this works with unbuffered subscriber channels.
+	DeliverBuffered
+)
+
+func (p DeliveryPolicy) String() string {
+	switch p {
+	case DeliverDrop:
+		return "drop"
+	case DeliverBlock:
+		return "block"
+	case DeliverBuffered:
+		return "buffered"
+	default:
+		return "unknown"
+	}
+}
+
+// SubscribeOpts configures which events a subscriber receives and how they are delivered.
+type SubscribeOpts struct {
+	// Name identifies the subscriber in the router stats.
+	Name string
+	// HostPort limits events to a single server. Empty receives events from all servers.
+	HostPort string
+	// Types limits events to the listed types. Empty, or including Any, receives events of all types.
+	Types  []EventType
+	Policy DeliveryPolicy
+	// Buffer is the size of the router side queue used by DeliverBuffered.
+	Buffer int
+}
+
+// SubscriptionStats are the delivery counters of a single subscriber.
+type SubscriptionStats struct {
+	Name      string
+	HostPort  string
+	Types     []EventType
+	Policy    DeliveryPolicy
+	Delivered int64
+	Dropped   int64
+}
+
+// TypedEvent is an Event with a payload of a known type, as received by SubscribeTyped subscribers.
+type TypedEvent[T any] struct {
+	HostPort  string
+	Type      EventType
+	Timestamp time.Time
+	Raw       string
+	Data      T
+}
+
+// Subscription is a registered subscriber of a Router. Cancel must be called once the subscriber stops
+// receiving, otherwise events continue to be counted as dropped, or block delivery for DeliverBlock.
+type Subscription struct {
+	router    *Router
+	opts      SubscribeOpts
+	accept    func(event Event) bool
+	send      func(event Event, block bool) bool
+	queue     chan Event
+	done      chan struct{}
+	once      *sync.Once
+	delivered atomic.Int64
+	dropped   atomic.Int64
+}
+
+// Cancel stops delivery of events to the subscriber. Any events queued by DeliverBuffered are discarded.
+// This is safe to call multiple times.
+func (s *Subscription) Cancel() {
+	s.once.Do(func() {
+		// Closed before taking the lock so that a blocked delivery is released.
+		close(s.done)
+		s.router.remove(s)
+	})
+}
+
+// Stats returns the current delivery counters of the subscriber.
+func (s *Subscription) Stats() SubscriptionStats {
+	return SubscriptionStats{
+		Name:      s.opts.Name,
+		HostPort:  s.opts.HostPort,
+		Types:     slices.Clone(s.opts.Types),
+		Policy:    s.opts.Policy,
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+	}
+}
+
+func (s *Subscription) matches(event Event) bool {
+	if s.opts.HostPort != "" && s.opts.HostPort != event.HostPort {
+		return false
+	}
+
+	if len(s.opts.Types) > 0 && !slices.Contains(s.opts.Types, Any) && !slices.Contains(s.opts.Types, event.Type) {
+		return false
+	}
+
+	return s.accept == nil || s.accept(event)
+}
+
+func (s *Subscription) deliver(event Event) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	if !s.matches(event) {
+		return
+	}
+
+	switch s.opts.Policy {
+	case DeliverBlock:
+		if s.send(event, true) {
+			s.delivered.Add(1)
+		}
+	case DeliverBuffered:
+		select {
+		case s.queue <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	default:
+		if s.send(event, false) {
+			s.delivered.Add(1)
+		} else {
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// forward sends the events queued by DeliverBuffered to the subscriber until cancelled.
+func (s *Subscription) forward() {
+	for {
+		select {
+		case event := <-s.queue:
+			if s.send(event, true) {
+				s.delivered.Add(1)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// newSender creates the function used to send events to the subscriber channel. When block is false, the
+// event is only sent if the channel is ready. Blocking sends are released when the subscription is cancelled.
+func newSender[T any](handler chan<- T, done <-chan struct{}, convert func(event Event) T) func(Event, bool) bool {
+	return func(event Event, block bool) bool {
+		value := convert(event)
+		if !block {
+			select {
+			case handler <- value:
+				return true
+			default:
+				return false
+			}
+		}
+
+		select {
+		case handler <- value:
+			return true
+		case <-done:
+			return false
+		}
+	}
+}
+
 func NewRouter() *Router {
 	return &Router{
-		parser:     NewParser(),
-		readers:    make(map[string]map[EventType][]chan<- Event),
-		readersAny: make(map[string][]chan<- Event),
-		readersMu:  &sync.RWMutex{},
+		parser:    NewParser(),
+		logParser: NewLogParser(),
+		readersMu: &sync.RWMutex{},
 	}
 }
 
 // Router handles receiving raw log line events from a console.Source, parsing them into
 // a Event and sending the parsed event to any registered handlers for the parsed event.
 type Router struct {
-	config     config.Config
-	readersAll []chan<- Event
-	readersAny map[string][]chan<- Event
-	readers    map[string]map[EventType][]chan<- Event
-	readersMu  *sync.RWMutex
-	parser     *Parser
+	config config.Config
+	// readers is replaced rather than modified so that it can be iterated without holding the lock, as
+	// delivery may block.
+	readers   []*Subscription
+	readersMu *sync.RWMutex
+	parser    *Parser
+	logParser *LogParser
 }
 
-// ListenFor registers a channel to start receiving events for the specified event.
-func (r *Router) ListenFor(hostPort string, handler chan<- Event, logTypes ...EventType) {
-	r.readersMu.Lock()
-	defer r.readersMu.Unlock()
+// ListenFor registers a channel to start receiving events for the specified event. An empty hostPort receives
+// events from all servers. Events are dropped when the channel is full.
+func (r *Router) ListenFor(hostPort string, handler chan<- Event, logTypes ...EventType) *Subscription {
+	return r.Subscribe(handler, SubscribeOpts{HostPort: hostPort, Types: logTypes})
+}
 
-	for _, logType := range logTypes {
-		if hostPort == "" {
-			r.readersAll = append(r.readersAll, handler)
+// Subscribe registers a channel to start receiving the events matching the options.
+func (r *Router) Subscribe(handler chan<- Event, opts SubscribeOpts) *Subscription {
+	return r.subscribe(opts, nil, func(done <-chan struct{}) func(Event, bool) bool {
+		return newSender(handler, done, func(event Event) Event { return event })
+	})
+}
 
-			continue
-		}
+// SubscribeTyped registers a channel to start receiving only the events with a payload of type T, such as
+// KillEvent, so that the payload does not need to be type switched on by the subscriber.
+func SubscribeTyped[T any](router *Router, handler chan<- TypedEvent[T], opts SubscribeOpts) *Subscription {
+	accept := func(event Event) bool {
+		_, ok := event.Data.(T)
+
+		return ok
+	}
+
+	return router.subscribe(opts, accept, func(done <-chan struct{}) func(Event, bool) bool {
+		return newSender(handler, done, func(event Event) TypedEvent[T] {
+			data, _ := event.Data.(T)
 
-		// Any case is handled more generally
-		if logType == Any {
-			if _, found := r.readersAny[hostPort]; !found {
-				r.readersAny[hostPort] = make([]chan<- Event, 0)
+			return TypedEvent[T]{
+				HostPort:  event.HostPort,
+				Type:      event.Type,
+				Timestamp: event.Timestamp,
+				Raw:       event.Raw,
+				Data:      data,
 			}
-			r.readersAny[hostPort] = append(r.readersAny[hostPort], handler)
+		})
+	})
+}
 
-			break
-		}
+func (r *Router) subscribe(opts SubscribeOpts, accept func(Event) bool,
+	newSend func(done <-chan struct{}) func(Event, bool) bool,
+) *Subscription {
+	subscription := &Subscription{
+		router: r,
+		opts:   opts,
+		accept: accept,
+		done:   make(chan struct{}),
+		once:   &sync.Once{},
+	}
+	subscription.send = newSend(subscription.done)
 
-		if _, found := r.readers[hostPort]; !found {
-			r.readers[hostPort] = make(map[EventType][]chan<- Event)
+	if opts.Policy == DeliverBuffered {
+		buffer := opts.Buffer
+		if buffer <= 0 {
+			buffer = defaultSubscriptionBuffer
 		}
 
-		if _, found := r.readers[hostPort][logType]; !found {
-			r.readers[hostPort][logType] = make([]chan<- Event, 0)
-		}
+		subscription.queue = make(chan Event, buffer)
+		go subscription.forward()
+	}
+
+	r.readersMu.Lock()
+	defer r.readersMu.Unlock()
+
+	r.readers = append(slices.Clone(r.readers), subscription)
 
-		r.readers[hostPort][logType] = append(r.readers[hostPort][logType], handler)
+	return subscription
+}
+
+func (r *Router) remove(subscription *Subscription) {
+	r.readersMu.Lock()
+	defer r.readersMu.Unlock()
+
+	r.readers = slices.DeleteFunc(slices.Clone(r.readers), func(reader *Subscription) bool {
+		return reader == subscription
+	})
+}
+
+// Stats returns the delivery counters of all current subscribers.
+func (r *Router) Stats() []SubscriptionStats {
+	r.readersMu.RLock()
+	readers := r.readers
+	r.readersMu.RUnlock()
+
+	stats := make([]SubscriptionStats, len(readers))
+	for idx, reader := range readers {
+		stats[idx] = reader.Stats()
 	}
+
+	return stats
 }
 
 // Send is responding for parsing and sending the result to any matching registered channels.
 func (r *Router) Send(hostPort string, line string) {
 	// TODO move the parser outside of the router, instead sending already parsed events to the router instead.
-	logEvent, err := r.parser.Parse(line)
+	parse := r.parser.Parse
+	if IsLogLine(line) {
+		// Lines sent by srcds in server mode use a different format to the client console.
+		parse = r.logParser.Parse
+	}
+
+	logEvent, err := parse(line)
 	if err != nil || errors.Is(err, ErrNoMatch) {
 		logEvent.Type = Any
 		logEvent.Raw = line
+		logEvent.Timestamp, _ = splitTimestamp(line)
 	}
 	logEvent.HostPort = hostPort
 
 	r.readersMu.RLock()
-	defer r.readersMu.RUnlock()
-
-	if handlers, found := r.readers[hostPort][logEvent.Type]; found {
-		for _, handler := range handlers {
-			select {
-			case handler <- logEvent:
-			default:
-				// slog.Warn("Failed to send event", slog.String("event", line), slog.String("host", hostPort))
-			}
-		}
-	}
-
-	anyHandlers, found := r.readersAny[hostPort]
-	if found {
-		for _, handler := range anyHandlers {
-			// handler <- logEvent
-			select {
-			case handler <- logEvent:
-			default:
-				// slog.Warn("Failed to send event", slog.String("event", line), slog.String("host", hostPort))
-			}
-		}
-	}
+	readers := r.readers
+	r.readersMu.RUnlock()
 
-	for _, handler := range r.readersAll {
-		// handler <- logEvent
-		select {
-		case handler <- logEvent:
-		default:
-			// slog.Warn("Failed to send event", slog.String("event", line), slog.String("host", hostPort))
-		}
+	for _, reader := range readers {
+		reader.deliver(logEvent)
 	}
 }
@@ -0,0 +1,142 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	routerHost  = "1.1.1.1:27015"
+	routerKill  = "Player One killed Player Two with scattergun."
+	routerChat  = "Player One :  hello"
+	otherServer = "2.2.2.2:27015"
+)
+
+func TestRouterListenFor(t *testing.T) {
+	router := events.NewRouter()
+
+	kills := make(chan events.Event, 10)
+	killSubscription := router.ListenFor(routerHost, kills, events.Kill)
+
+	all := make(chan events.Event, 10)
+	router.ListenFor("", all, events.Any)
+
+	router.Send(routerHost, routerChat)
+	router.Send(routerHost, routerKill)
+	router.Send(otherServer, routerKill)
+
+	require.Len(t, kills, 1)
+	require.Len(t, all, 3)
+	require.Equal(t, events.Kill, (<-kills).Type)
+
+	killSubscription.Cancel()
+	killSubscription.Cancel()
+	router.Send(routerHost, routerKill)
+
+	require.Empty(t, kills)
+	require.Len(t, router.Stats(), 1)
+}
+
+func TestRouterDropped(t *testing.T) {
+	router := events.NewRouter()
+
+	handler := make(chan events.Event, 1)
+	subscription := router.Subscribe(handler, events.SubscribeOpts{Name: "slow"})
+
+	for range 3 {
+		router.Send(routerHost, routerChat)
+	}
+
+	stats := subscription.Stats()
+	require.Equal(t, "slow", stats.Name)
+	require.Equal(t, events.DeliverDrop, stats.Policy)
+	require.Equal(t, int64(1), stats.Delivered)
+	require.Equal(t, int64(2), stats.Dropped)
+	require.Equal(t, []events.SubscriptionStats{stats}, router.Stats())
+}
+
+func TestRouterDeliverBlock(t *testing.T) {
+	router := events.NewRouter()
+
+	handler := make(chan events.Event)
+	subscription := router.Subscribe(handler, events.SubscribeOpts{Policy: events.DeliverBlock})
+
+	sent := make(chan struct{})
+	go func() {
+		router.Send(routerHost, routerChat)
+		router.Send(routerHost, routerKill)
+		close(sent)
+	}()
+
+	require.Equal(t, events.Msg, (<-handler).Type)
+	require.Equal(t, events.Kill, (<-handler).Type)
+	<-sent
+
+	// Cancelling releases a blocked send.
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		subscription.Cancel()
+	}()
+
+	router.Send(routerHost, routerChat)
+	require.Equal(t, int64(2), subscription.Stats().Delivered)
+	require.Zero(t, subscription.Stats().Dropped)
+}
+
+func TestRouterDeliverBuffered(t *testing.T) {
+	router := events.NewRouter()
+
+	handler := make(chan events.Event)
+	subscription := router.Subscribe(handler, events.SubscribeOpts{Policy: events.DeliverBuffered, Buffer: 2})
+	t.Cleanup(subscription.Cancel)
+
+	// Queued within the router until the handler is ready, even though the channel itself is unbuffered.
+	router.Send(routerHost, routerChat)
+	router.Send(routerHost, routerKill)
+
+	for _, expected := range []events.EventType{events.Msg, events.Kill} {
+		select {
+		case event := <-handler:
+			require.Equal(t, expected, event.Type)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for buffered event")
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return subscription.Stats().Delivered == 2
+	}, time.Second, time.Millisecond*10)
+	require.Zero(t, subscription.Stats().Dropped)
+
+	// Once the queue is full the rest are dropped. The forwarder may also be holding one event.
+	for range 10 {
+		router.Send(routerHost, routerChat)
+	}
+
+	require.InDelta(t, 7.5, float64(subscription.Stats().Dropped), 0.5)
+}
+
+func TestSubscribeTyped(t *testing.T) {
+	router := events.NewRouter()
+
+	kills := make(chan events.TypedEvent[events.KillEvent], 10)
+	subscription := events.SubscribeTyped(router, kills, events.SubscribeOpts{Name: "kills", HostPort: routerHost})
+	t.Cleanup(subscription.Cancel)
+
+	router.Send(routerHost, routerChat)
+	router.Send(routerHost, routerKill)
+	router.Send(otherServer, routerKill)
+
+	require.Len(t, kills, 1)
+
+	kill := <-kills
+	require.Equal(t, routerHost, kill.HostPort)
+	require.Equal(t, events.Kill, kill.Type)
+	require.Equal(t, routerKill, kill.Raw)
+	require.Equal(t, "Player One", kill.Data.Player)
+	require.Equal(t, "Player Two", kill.Data.Victim)
+	require.Equal(t, int64(1), subscription.Stats().Delivered)
+}
@@ -20,10 +20,9 @@ import (
 
 var ErrDumpQuery = errors.New("failed to perform dump query")
 
-func NewFetcher(address string, password string, serverMode bool) Fetcher {
+func NewFetcher(conn *Conn, serverMode bool) Fetcher {
 	return Fetcher{
-		Address:    address,
-		Password:   password,
+		conn:       conn,
 		serverMode: serverMode,
 		g15re:      regexp.MustCompile(`^(m_szName|m_iPing|m_iScore|m_iDeaths|m_bConnected|m_iTeam|m_bAlive|m_iHealth|m_iAccountID|m_bValid|m_iUserID)\[(\d+)]\s(integer|bool|string)\s\((.+?)?\)$`),
 		// CPU    In_(KB/s)  Out_(KB/s)  Uptime  Map_changes  FPS      Players  Connects
@@ -33,8 +32,7 @@ func NewFetcher(address string, password string, serverMode bool) Fetcher {
 }
 
 type Fetcher struct {
-	Address    string
-	Password   string
+	conn       *Conn
 	lastUpdate tf.DumpPlayer
 	lastStatus tf.Status
 	serverMode bool
@@ -47,10 +45,10 @@ func (f Fetcher) Fetch(ctx context.Context) (tf.DumpPlayer, tf.Status, error) {
 	if f.serverMode {
 		command += ";stats"
 	} else {
-		command += ";g15_dumpplayer"
+		command += ";g15_dumpplayer;tf_lobby_debug"
 	}
 
-	response, errExec := New(f.Address, f.Password).Exec(ctx, command, true)
+	response, errExec := f.conn.Exec(ctx, command, true)
 	if errExec != nil {
 		if f.lastUpdate.SteamID[0].Valid() {
 			return f.lastUpdate, f.lastStatus, nil
@@ -126,15 +124,13 @@ func (f Fetcher) Fetch(ctx context.Context) (tf.DumpPlayer, tf.Status, error) {
 			dump.State[idx] = player.State
 			dump.Time[idx] = int(player.ConnectedTime.Seconds())
 			dump.UserID[idx] = player.UserID
-			// We have no way of knowing their real teams in server mode.
-			if idx%2 == 0 {
-				dump.Team[idx] = tf.BLU
-			} else {
-				dump.Team[idx] = tf.RED
-			}
+			// Teams are not available over rcon in server mode, they are tracked from the logs instead.
+			dump.Team[idx] = tf.UNASSIGNED
 		}
 	} else {
 		dump = f.parsePlayerState(strings.NewReader(response))
+		// Lobby members include players assigned by matchmaking that have not finished connecting yet.
+		f.lastStatus.Lobby = tf.ParseLobby(response)
 	}
 
 	f.lastUpdate = dump
@@ -0,0 +1,101 @@
+package rcon_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/rcon"
+	"github.com/leighmacdonald/tf-tui/internal/tf/srcdstest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *srcdstest.Server {
+	t.Helper()
+
+	server, errServer := srcdstest.NewServer(srcdstest.Options{
+		Password: testPassword,
+		Hostname: "Test Server",
+		Map:      "pl_upward",
+		Stats:    tf.Stats{CPU: 12.5, InKBs: 80.25, OutKBs: 600.5, Uptime: 120, MapChanges: 3, FPS: 66.67, Connects: 42},
+	})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	server.SetPlayers(
+		srcdstest.Player{
+			UserID: 2, Name: "Player One", SteamID: steamid.New(76561197960265730), Team: tf.RED, Ping: 50,
+			Score: 10, Health: 125, Alive: true, Connected: time.Minute * 75, Address: "10.0.0.1:27005",
+		},
+		srcdstest.Player{
+			UserID: 3, Name: "Player Two", SteamID: steamid.New(76561197970669109), Team: tf.BLU, Ping: 75,
+			Deaths: 4, Connected: time.Minute * 5, Address: "10.0.0.2:27005",
+		},
+	)
+
+	return server
+}
+
+func TestFetchClient(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := rcon.NewFetcher(newTestConn(t, server.Address(), testPassword), false)
+
+	dump, _, errFetch := fetcher.Fetch(t.Context())
+	require.NoError(t, errFetch)
+
+	require.Equal(t, "Player One", dump.Names[1])
+	require.Equal(t, steamid.New(76561197960265730), dump.SteamID[1])
+	require.Equal(t, tf.RED, dump.Team[1])
+	require.Equal(t, 125, dump.Health[1])
+	require.True(t, dump.Alive[1])
+	require.Equal(t, "Player Two", dump.Names[2])
+	require.Equal(t, tf.BLU, dump.Team[2])
+	require.Equal(t, 4, dump.Deaths[2])
+	require.Equal(t, 3, dump.UserID[2])
+}
+
+func TestFetchServer(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := rcon.NewFetcher(newTestConn(t, server.Address(), testPassword), true)
+
+	dump, status, errFetch := fetcher.Fetch(t.Context())
+	require.NoError(t, errFetch)
+
+	require.Equal(t, "Test Server", status.ServerName)
+	require.Equal(t, "pl_upward", status.Map)
+	require.Len(t, status.Players, 2)
+	require.InDelta(t, 66.67, status.Stats.FPS, 0.01)
+	require.Equal(t, 42, status.Stats.Connects)
+
+	require.Equal(t, "Player One", dump.Names[0])
+	require.Equal(t, steamid.New(76561197960265730), dump.SteamID[0])
+	require.Equal(t, 75*60, dump.Time[0])
+	require.Equal(t, "10.0.0.2:27005", dump.Address[1])
+}
+
+func TestFetchClientLobby(t *testing.T) {
+	server, errServer := srcdstest.NewServer(srcdstest.Options{
+		Password: testPassword,
+		Handlers: map[string]srcdstest.Handler{
+			"tf_lobby_debug": func(_ string) string {
+				return "CTFLobbyShared: ID:00021f0d1d2a6d41  1 member(s), 1 pending\n" +
+					"  Member[0] [U:1:1]  team = TF_GC_TEAM_DEFENDERS  type = MATCH_PLAYER\n" +
+					"  Pending[0] [U:1:2]  team = TF_GC_TEAM_INVADERS  type = MATCH_PLAYER\n"
+			},
+		},
+	})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	fetcher := rcon.NewFetcher(newTestConn(t, server.Address(), testPassword), false)
+
+	_, status, errFetch := fetcher.Fetch(t.Context())
+	require.NoError(t, errFetch)
+
+	require.Equal(t, "00021f0d1d2a6d41", status.Lobby.ID)
+	require.Equal(t, []tf.LobbyMember{
+		{SteamID: steamid.New("[U:1:1]"), Team: tf.RED},
+		{SteamID: steamid.New("[U:1:2]"), Team: tf.BLU, Pending: true},
+	}, status.Lobby.Members)
+}
@@ -0,0 +1,84 @@
+package rcon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Packet types used by the source rcon protocol. Note that exec and auth response share the same value
+// and are distinguished by the direction they are sent.
+const (
+	packetResponseValue int32 = 0
+	packetExecCommand   int32 = 2
+	packetAuthResponse  int32 = 2
+	packetAuth          int32 = 3
+)
+
+const (
+	// packetHeaderSize is the size of the id and type fields, plus the two null terminators, that are
+	// included in the size field of every packet.
+	packetHeaderSize = 10
+	// maxPacketSize is the largest packet size that will be accepted. The game itself splits responses at
+	// 4096 bytes, but this is left larger to be lenient with modded servers.
+	maxPacketSize = 1 << 16
+	// maxCommandSize is the largest command body accepted by the game.
+	maxCommandSize = 4096 - packetHeaderSize
+)
+
+var (
+	ErrPacketSize  = errors.New("invalid rcon packet size")
+	ErrCommandSize = errors.New("rcon command too long")
+)
+
+type packet struct {
+	id   int32
+	kind int32
+	body string
+}
+
+// marshal encodes the packet into its wire format.
+func (p packet) marshal() ([]byte, error) {
+	if len(p.body) > maxCommandSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrCommandSize, len(p.body))
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, 4+packetHeaderSize+len(p.body)))
+	// Writes to a bytes.Buffer cannot fail.
+	_ = binary.Write(buffer, binary.LittleEndian, int32(packetHeaderSize+len(p.body)))
+	_ = binary.Write(buffer, binary.LittleEndian, p.id)
+	_ = binary.Write(buffer, binary.LittleEndian, p.kind)
+	buffer.WriteString(p.body)
+	buffer.Write([]byte{0, 0})
+
+	return buffer.Bytes(), nil
+}
+
+// readPacket reads a single complete packet from the reader.
+func readPacket(reader io.Reader) (packet, error) {
+	var size int32
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return packet{}, err
+	}
+
+	if size < packetHeaderSize || size > maxPacketSize {
+		return packet{}, fmt.Errorf("%w: %d", ErrPacketSize, size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return packet{}, err
+	}
+
+	// The body is null terminated and followed by an empty string. This is not validated since the game
+	// replies to an empty response value with a malformed packet whose body is 0x00 0x01 0x00 0x00.
+	body, _, _ := bytes.Cut(data[8:], []byte{0})
+
+	return packet{
+		id:   int32(binary.LittleEndian.Uint32(data[0:4])), //nolint:gosec
+		kind: int32(binary.LittleEndian.Uint32(data[4:8])), //nolint:gosec
+		body: string(body),
+	}, nil
+}
@@ -0,0 +1,53 @@
+package rcon
+
+import (
+	"errors"
+	"sync"
+)
+
+// Pool holds a single persistent connection for each server so that the connection does not need to be
+// re-established for every command.
+type Pool struct {
+	mu    *sync.Mutex
+	conns map[string]*Conn
+}
+
+func NewPool() *Pool {
+	return &Pool{mu: &sync.Mutex{}, conns: map[string]*Conn{}}
+}
+
+// Get returns the connection for the server, creating it if it does not exist yet. The connection itself is
+// not established until the first command is executed.
+func (p *Pool) Get(address string, password string) *Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conn, found := p.conns[address]
+	if !found || conn.password != password {
+		if found {
+			_ = conn.Close()
+		}
+
+		conn = newConn(address, password, defaultTimeout)
+		p.conns[address] = conn
+	}
+
+	return conn
+}
+
+// Close closes all connections in the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for address, conn := range p.conns {
+		if errClose := conn.Close(); errClose != nil {
+			err = errors.Join(err, errClose)
+		}
+
+		delete(p.conns, address)
+	}
+
+	return err
+}
@@ -1,91 +1,469 @@
 package rcon
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"strings"
+	"sync"
 	"time"
+)
+
+const (
+	// defaultTimeout is used for both establishing connections and waiting for command responses.
+	defaultTimeout = time.Second * 10
+	minBackoff     = time.Second
+	maxBackoff     = time.Minute
+)
 
-	"github.com/leighmacdonald/rcon/rcon"
+var (
+	errRCON    = errors.New("errors making rcon request")
+	ErrAuth    = errors.New("rcon authentication failed")
+	ErrBackoff = errors.New("rcon connection is waiting to reconnect")
+	ErrClosed  = errors.New("rcon connection closed")
+	ErrTimeout = errors.New("rcon request timed out")
 )
 
-var errRCON = errors.New("errors making rcon request")
+// Health describes the current state of a pooled connection.
+type Health struct {
+	Connected bool
+	// Failures is the number of consecutive failed connection attempts or dropped connections.
+	Failures  int
+	LastError string
+	// Latency is the round trip time of the last successful command.
+	Latency time.Duration
+	// NextAttempt is when a new connection will be attempted after a failure.
+	NextAttempt time.Time
+	// Commands is the total number of commands executed, including failed ones.
+	Commands uint64
+	// Errors is the total number of commands that failed or timed out.
+	Errors uint64
+}
+
+type result struct {
+	body string
+	err  error
+}
+
+type request struct {
+	id    int32
+	large bool
+	// sentinelID is the id of the empty response value packet sent after large commands. The game
+	// mirrors it back once it has sent every packet of the actual response.
+	sentinelID int32
+	body       strings.Builder
+	done       chan result
+}
 
-type Connection struct {
-	addr     string
+// Conn is a long-lived, authenticated rcon connection to a single server. Multiple commands can be in flight
+// at once with the responses being matched back to the command using the request id. The connection is
+// established lazily and re-established with an exponential backoff when it drops.
+type Conn struct {
+	address  string
 	password string
 	timeout  time.Duration
+	mu       *sync.Mutex
+	conn     net.Conn
+	// connecting is closed once the connection attempt in progress has finished. Connections are established
+	// without holding mu, as dialing an unreachable server can take up to the full timeout.
+	connecting chan struct{}
+	pending    map[int32]*request
+	nextID     int32
+	backoff    time.Duration
+	closed     bool
+	// healthMu is separate from mu so that the health can always be read without waiting on the connection.
+	healthMu *sync.Mutex
+	health   Health
 }
 
-func New(addr string, password string) Connection {
-	return Connection{
-		addr:     addr,
+func newConn(address string, password string, timeout time.Duration) *Conn {
+	return &Conn{
+		address:  address,
 		password: password,
-		timeout:  time.Second,
+		timeout:  timeout,
+		mu:       &sync.Mutex{},
+		pending:  map[int32]*request{},
+		healthMu: &sync.Mutex{},
 	}
 }
 
-func (r Connection) Exec(ctx context.Context, cmd string, large bool) (string, error) {
-	conn, errConn := rcon.Dial(ctx, r.addr, r.password, r.timeout)
-	if errConn != nil {
-		return "", errors.Join(errConn, fmt.Errorf("%w: %s", errRCON, r.addr))
+// Address returns the address of the server.
+func (c *Conn) Address() string {
+	return c.address
+}
+
+// Health returns the current state of the connection.
+func (c *Conn) Health() Health {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	return c.health
+}
+
+// updateHealth applies the update to the health while holding its lock.
+func (c *Conn) updateHealth(update func(health *Health)) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	update(&c.health)
+}
+
+// Exec runs the command on the server and returns its output. Large should be set for commands where the
+// response can exceed the size of a single packet, such as cvarlist or g15_dumpplayer. Each command is
+// given at most the connection timeout to complete, or less if the context deadline is sooner.
+func (c *Conn) Exec(ctx context.Context, cmd string, large bool) (string, error) {
+	started := time.Now()
+
+	req, errSend := c.send(ctx, cmd, large)
+	if errSend != nil {
+		c.countFailure()
+
+		return "", errors.Join(errSend, fmt.Errorf("%w: %s", errRCON, c.address))
 	}
-	defer func() {
-		if err := conn.Close(); err != nil {
-			slog.Error("failed to close rcon connection", slog.String("error", err.Error()))
+
+	timeout, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	select {
+	case res := <-req.done:
+		if res.err != nil {
+			c.countFailure()
+
+			return "", errors.Join(res.err, fmt.Errorf("%w: %s", errRCON, c.address))
 		}
-	}()
 
+		c.updateHealth(func(health *Health) {
+			health.Latency = time.Since(started)
+			health.Commands++
+		})
+
+		return res.body, nil
+	case <-timeout.Done():
+		c.mu.Lock()
+		delete(c.pending, req.id)
+		delete(c.pending, req.sentinelID)
+		c.mu.Unlock()
+
+		c.countFailure()
+
+		return "", errors.Join(timeout.Err(), ErrTimeout, fmt.Errorf("%w: %s", errRCON, c.address))
+	}
+}
+
+func (c *Conn) countFailure() {
+	c.updateHealth(func(health *Health) {
+		health.Commands++
+		health.Errors++
+	})
+}
+
+// Close closes the connection. Any in flight commands are failed and further commands are rejected.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Close()
+	c.conn = nil
+	c.failPendingLocked(ErrClosed)
+
+	return err
+}
+
+func (c *Conn) send(ctx context.Context, cmd string, large bool) (*request, error) {
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		// Dropped again between connecting and sending.
+		return nil, ErrClosed
+	}
+
+	req := &request{id: c.newIDLocked(), large: large, done: make(chan result, 1)}
+	payload, errMarshal := packet{id: req.id, kind: packetExecCommand, body: cmd}.marshal()
+	if errMarshal != nil {
+		return nil, errMarshal
+	}
+
+	payloads := [][]byte{payload}
 	if large {
-		return r.rconLarge(conn, cmd)
+		// Responses larger than a single packet are split by the game into multiple packets with no indication
+		// of which is the last one. Since commands are processed in order, following up with an empty response
+		// value, which the game mirrors back, marks the end of the response.
+		req.sentinelID = c.newIDLocked()
+		sentinel, errSentinel := packet{id: req.sentinelID, kind: packetResponseValue}.marshal()
+		if errSentinel != nil {
+			return nil, errSentinel
+		}
+
+		payloads = append(payloads, sentinel)
+		c.pending[req.sentinelID] = req
+	}
+
+	c.pending[req.id] = req
+
+	if err := c.write(payloads...); err != nil {
+		delete(c.pending, req.id)
+		delete(c.pending, req.sentinelID)
+		c.disconnectLocked(err)
+
+		return nil, err
 	}
 
-	return r.rcon(conn, cmd)
+	return req, nil
 }
 
-func (r Connection) rcon(conn *rcon.RemoteConsole, cmd string) (string, error) {
-	cmdID, errWrite := conn.Write(cmd)
-	if errWrite != nil {
-		return "", errors.Join(errWrite, errRCON)
+func (c *Conn) write(payloads ...[]byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
 	}
 
-	resp, respID, errRead := conn.Read()
-	if errRead != nil {
-		return "", errors.Join(errRead, errRCON)
+	for _, payload := range payloads {
+		if _, err := c.conn.Write(payload); err != nil {
+			return err
+		}
 	}
 
-	if respID != cmdID {
-		slog.Warn("Mismatched command response ID", slog.Int("req", cmdID), slog.Int("resp", respID))
+	return nil
+}
+
+func (c *Conn) newIDLocked() int32 {
+	c.nextID++
+	if c.nextID <= 0 {
+		c.nextID = 1
 	}
 
-	return resp, nil
+	return c.nextID
 }
 
-// rconLarge is used for rcon responses that exceed the size of a single rcon packet (g15_dumpplayer).
-func (r Connection) rconLarge(conn *rcon.RemoteConsole, cmd string) (string, error) {
-	cmdID, errWrite := conn.Write(cmd)
-	if errWrite != nil {
-		return "", errors.Join(errWrite, errRCON)
+// connect makes sure the connection is established. Only a single caller dials the server at a time, others
+// wait for that attempt to finish and then use its result.
+func (c *Conn) connect(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+
+			return ErrClosed
+		}
+
+		if c.conn != nil {
+			c.mu.Unlock()
+
+			return nil
+		}
+
+		if connecting := c.connecting; connecting != nil {
+			c.mu.Unlock()
+
+			select {
+			case <-connecting:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if time.Now().Before(c.Health().NextAttempt) {
+			c.mu.Unlock()
+
+			return ErrBackoff
+		}
+
+		connecting := make(chan struct{})
+		c.connecting = connecting
+		authID := c.newIDLocked()
+		c.mu.Unlock()
+
+		conn, reader, errDial := c.dial(ctx, authID)
+
+		c.mu.Lock()
+		c.connecting = nil
+		close(connecting)
+
+		if errDial != nil {
+			c.failLocked(errDial)
+			c.mu.Unlock()
+
+			return errDial
+		}
+
+		if c.closed {
+			c.mu.Unlock()
+			_ = conn.Close()
+
+			return ErrClosed
+		}
+
+		c.conn = conn
+		c.backoff = 0
+		c.updateHealth(func(health *Health) {
+			health.Connected = true
+			health.Failures = 0
+			health.LastError = ""
+		})
+		c.mu.Unlock()
+
+		go c.readLoop(conn, reader)
+
+		slog.Debug("Connected to rcon", slog.String("address", c.address))
+
+		return nil
+	}
+}
+
+// dial connects and authenticates with the server. This must not be called while holding mu.
+func (c *Conn) dial(ctx context.Context, authID int32) (net.Conn, *bufio.Reader, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, errDial := dialer.DialContext(ctx, "tcp", c.address)
+	if errDial != nil {
+		return nil, nil, errDial
 	}
 
-	var response string
+	// Abort the authentication if the caller gives up, rather than waiting for the full timeout.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+
+	reader := bufio.NewReader(conn)
+	errAuth := c.authenticate(conn, reader, authID)
+
+	// The deadline may have been set after authenticate cleared it, so the connection cannot be used.
+	if !stop() && errAuth == nil {
+		errAuth = ctx.Err()
+	}
+
+	if errAuth != nil {
+		_ = conn.Close()
+
+		return nil, nil, errAuth
+	}
+
+	return conn, reader, nil
+}
+
+func (c *Conn) authenticate(conn net.Conn, reader *bufio.Reader, authID int32) error {
+	if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+
+	payload, errMarshal := packet{id: authID, kind: packetAuth, body: c.password}.marshal()
+	if errMarshal != nil {
+		return errMarshal
+	}
+
+	if _, err := conn.Write(payload); err != nil {
+		return err
+	}
 
 	for {
-		resp, respID, errRead := conn.Read()
+		resp, errRead := readPacket(reader)
 		if errRead != nil {
-			return "", errors.Join(errRead, errRCON)
+			return errRead
 		}
 
-		if cmdID == respID {
-			s := len(resp)
-			response += resp
+		// The game sends an empty response value before the actual auth response.
+		if resp.kind != packetAuthResponse {
+			continue
+		}
 
-			if s < 4096 {
-				break
+		if resp.id != authID {
+			return ErrAuth
+		}
+
+		break
+	}
+
+	// Clear the deadlines, the connection is expected to live for as long as the app is running.
+	return conn.SetDeadline(time.Time{})
+}
+
+func (c *Conn) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		resp, errRead := readPacket(reader)
+		if errRead != nil {
+			c.mu.Lock()
+			// Only tear down the connection if it has not already been replaced or closed.
+			if c.conn == conn {
+				c.disconnectLocked(errRead)
 			}
+			c.mu.Unlock()
+
+			return
 		}
+
+		c.dispatch(resp)
+	}
+}
+
+// dispatch routes a response packet to the request waiting for it.
+func (c *Conn) dispatch(resp packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	req, found := c.pending[resp.id]
+	if !found || resp.kind != packetResponseValue {
+		return
+	}
+
+	if req.large && resp.id != req.sentinelID {
+		req.body.WriteString(resp.body)
+
+		return
 	}
 
-	return response, nil
+	if !req.large {
+		req.body.WriteString(resp.body)
+	}
+
+	c.finishLocked(req, result{body: req.body.String()})
+}
+
+// finishLocked removes the request from the pending set and delivers its result.
+func (c *Conn) finishLocked(req *request, res result) {
+	delete(c.pending, req.id)
+	delete(c.pending, req.sentinelID)
+
+	req.done <- res
+}
+
+// disconnectLocked closes the current connection after an error, failing all in flight requests.
+func (c *Conn) disconnectLocked(err error) {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+
+	c.failPendingLocked(err)
+	c.failLocked(err)
+
+	slog.Warn("Lost rcon connection", slog.String("address", c.address), slog.String("error", err.Error()),
+		slog.Duration("retry_in", c.backoff))
+}
+
+func (c *Conn) failPendingLocked(err error) {
+	// Large requests are registered under two ids, finishing them removes both so they are only failed once.
+	for _, req := range c.pending {
+		c.finishLocked(req, result{err: err})
+	}
+}
+
+// failLocked records a failure and schedules the next connection attempt.
+func (c *Conn) failLocked(err error) {
+	c.backoff = min(max(c.backoff*2, minBackoff), maxBackoff)
+	c.updateHealth(func(health *Health) {
+		health.Connected = false
+		health.Failures++
+		health.LastError = err.Error()
+		health.NextAttempt = time.Now().Add(c.backoff)
+	})
 }
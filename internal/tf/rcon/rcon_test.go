@@ -0,0 +1,209 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/tf-tui/internal/tf/rcon"
+	"github.com/leighmacdonald/tf-tui/internal/tf/srcdstest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPassword = "hunter2"
+	// testChunkSize matches the size the game splits responses at.
+	testChunkSize = 4096
+)
+
+// newResponseServer starts a fake server responding to each command with the matching response.
+func newResponseServer(t *testing.T, responses map[string]string) *srcdstest.Server {
+	t.Helper()
+
+	handlers := map[string]srcdstest.Handler{}
+	for command, response := range responses {
+		handlers[command] = func(_ string) string { return response }
+	}
+
+	server, errServer := srcdstest.NewServer(srcdstest.Options{Password: testPassword, Handlers: handlers})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	return server
+}
+
+func newTestConn(t *testing.T, address string, password string) *rcon.Conn {
+	t.Helper()
+
+	pool := rcon.NewPool()
+	t.Cleanup(func() { _ = pool.Close() })
+
+	return pool.Get(address, password)
+}
+
+func TestExecSmall(t *testing.T) {
+	server := newResponseServer(t, map[string]string{"echo": "hi\n"})
+	conn := newTestConn(t, server.Address(), testPassword)
+
+	response, errExec := conn.Exec(t.Context(), "echo hi", false)
+	require.NoError(t, errExec)
+	require.Equal(t, "hi\n", response)
+
+	health := conn.Health()
+	require.True(t, health.Connected)
+	require.Equal(t, uint64(1), health.Commands)
+	require.Zero(t, health.Errors)
+}
+
+func TestExecLarge(t *testing.T) {
+	responses := map[string]string{
+		"cvarlist":       strings.Repeat("a", testChunkSize*3+17),
+		"g15_dumpplayer": strings.Repeat("b", testChunkSize*2),
+		"status":         strings.Repeat("c", testChunkSize),
+		"empty":          "",
+	}
+
+	server := newResponseServer(t, responses)
+	conn := newTestConn(t, server.Address(), testPassword)
+
+	for command, expected := range responses {
+		response, errExec := conn.Exec(t.Context(), command, true)
+		require.NoError(t, errExec, command)
+		require.Equal(t, expected, response, command)
+	}
+}
+
+func TestExecConcurrent(t *testing.T) {
+	responses := map[string]string{}
+	for idx := range 20 {
+		responses[fmt.Sprintf("cmd_%d", idx)] = strings.Repeat(fmt.Sprintf("%d", idx%10), testChunkSize*(idx%3)+idx)
+	}
+
+	server := newResponseServer(t, responses)
+	conn := newTestConn(t, server.Address(), testPassword)
+
+	waitGroup := sync.WaitGroup{}
+	for command, expected := range responses {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			response, errExec := conn.Exec(t.Context(), command, true)
+			if !(errExec == nil && response == expected) {
+				t.Errorf("invalid response for %s: %v", command, errExec)
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+}
+
+func TestExecTimeout(t *testing.T) {
+	server, errServer := srcdstest.NewServer(srcdstest.Options{
+		Password: testPassword,
+		Handlers: map[string]srcdstest.Handler{
+			"echo": func(args string) string { return args },
+			"wait": func(_ string) string {
+				time.Sleep(time.Millisecond * 300)
+
+				return "late"
+			},
+		},
+	})
+	require.NoError(t, errServer)
+	t.Cleanup(func() { _ = server.Close() })
+
+	conn := newTestConn(t, server.Address(), testPassword)
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond*100)
+	defer cancel()
+
+	_, errExec := conn.Exec(ctx, "wait", true)
+	require.ErrorIs(t, errExec, rcon.ErrTimeout)
+
+	// The late response to the timed out command should not be mixed up with the following ones.
+	response, errExec := conn.Exec(t.Context(), "echo hi", true)
+	require.NoError(t, errExec)
+	require.Equal(t, "hi", response)
+}
+
+func TestAuthFailure(t *testing.T) {
+	server := newResponseServer(t, nil)
+	conn := newTestConn(t, server.Address(), "wrong")
+
+	_, errExec := conn.Exec(t.Context(), "status", true)
+	require.ErrorIs(t, errExec, rcon.ErrAuth)
+
+	health := conn.Health()
+	require.False(t, health.Connected)
+	require.Equal(t, 1, health.Failures)
+
+	// Reconnects are not attempted until the backoff has elapsed.
+	_, errExec = conn.Exec(t.Context(), "status", true)
+	require.ErrorIs(t, errExec, rcon.ErrBackoff)
+	require.Equal(t, uint64(2), conn.Health().Errors)
+}
+
+func TestDisconnect(t *testing.T) {
+	server := newResponseServer(t, nil)
+	conn := newTestConn(t, server.Address(), testPassword)
+
+	_, errExec := conn.Exec(t.Context(), "status", true)
+	require.NoError(t, errExec)
+	require.True(t, conn.Health().Connected)
+
+	require.NoError(t, server.Close())
+
+	_, errExec = conn.Exec(t.Context(), "status", true)
+	require.Error(t, errExec)
+	require.False(t, errors.Is(errExec, rcon.ErrTimeout))
+	require.False(t, conn.Health().Connected)
+}
+
+func TestHealthWhileConnecting(t *testing.T) {
+	// Connections are accepted by the kernel but never answered, the same as a server that has stopped
+	// responding, so the connection attempt hangs until it times out.
+	listener, errListen := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, errListen)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	conn := newTestConn(t, listener.Addr().String(), testPassword)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	connecting := make(chan error)
+
+	go func() {
+		_, errExec := conn.Exec(ctx, "status", true)
+		connecting <- errExec
+	}()
+
+	// Give the connection attempt time to start.
+	time.Sleep(time.Millisecond * 100)
+
+	started := time.Now()
+	require.False(t, conn.Health().Connected)
+	require.Less(t, time.Since(started), time.Millisecond*50)
+
+	// Other commands wait for the attempt in progress rather than starting their own.
+	waitCtx, waitCancel := context.WithTimeout(t.Context(), time.Millisecond*50)
+	defer waitCancel()
+
+	_, errWait := conn.Exec(waitCtx, "status", true)
+	require.ErrorIs(t, errWait, context.DeadlineExceeded)
+
+	cancel()
+
+	select {
+	case errExec := <-connecting:
+		require.Error(t, errExec)
+	case <-time.After(time.Second * 5):
+		t.Fatal("connection attempt was not cancelled")
+	}
+
+	require.Equal(t, 1, conn.Health().Failures)
+}
@@ -0,0 +1,126 @@
+package srcdstest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *Server) status() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("hostname: %s\n", s.opts.Hostname))
+	output.WriteString("version : 9543365/24 9543365 secure\n")
+	output.WriteString(fmt.Sprintf("udp/ip  : %s  (public ip: 127.0.0.1)\n", s.Address()))
+	output.WriteString("steamid : [G:1:1234567] (85568392921273687)\n")
+	output.WriteString("account : not logged in  (No account specified)\n")
+	output.WriteString(fmt.Sprintf("map     : %s at: 0 x, 0 y, 0 z\n", s.opts.Map))
+	output.WriteString(fmt.Sprintf("tags    : %s\n", strings.Join(s.opts.Tags, ",")))
+	output.WriteString(fmt.Sprintf("players : %d humans, 0 bots (%d max)\n", len(s.players), s.opts.MaxPlayers))
+	output.WriteString("edicts  : 1024 used of 2048 max\n")
+	output.WriteString("# userid name                uniqueid            connected ping loss state  adr\n")
+
+	for _, player := range s.players {
+		output.WriteString(fmt.Sprintf("#   %4d %-19s %-19s %9s %4d %4d active %s\n",
+			player.UserID, `"`+player.Name+`"`, player.SteamID.Steam3(), formatConnected(player.Connected),
+			player.Ping, player.Loss, player.Address))
+	}
+
+	return output.String()
+}
+
+// formatConnected formats the duration the same way as the connected column of the status command.
+func formatConnected(connected time.Duration) string {
+	seconds := int(connected.Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+func (s *Server) stats() string {
+	stats := s.opts.Stats
+
+	s.mu.Lock()
+	players := len(s.players)
+	s.mu.Unlock()
+
+	return fmt.Sprintf("CPU    In_(KB/s)  Out_(KB/s)  Uptime  Map_changes  FPS      Players  Connects\n"+
+		"%-6.2f %-10.2f %-11.2f %-7d %-12d %-8.2f %-8d %d\n",
+		stats.CPU, stats.InKBs, stats.OutKBs, stats.Uptime, stats.MapChanges, stats.FPS, players, stats.Connects)
+}
+
+// dumpPlayer renders the player table in the format of g15_dumpplayer. Index 0 is reserved for the world
+// entity so players start at index 1.
+func (s *Server) dumpPlayer() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var output strings.Builder
+	output.WriteString("Player info:\n")
+
+	for idx, player := range s.players {
+		slot := idx + 1
+		output.WriteString(fmt.Sprintf("m_szName[%d] string (%s)\n", slot, player.Name))
+		output.WriteString(fmt.Sprintf("m_iPing[%d] integer (%d)\n", slot, player.Ping))
+		output.WriteString(fmt.Sprintf("m_iScore[%d] integer (%d)\n", slot, player.Score))
+		output.WriteString(fmt.Sprintf("m_iDeaths[%d] integer (%d)\n", slot, player.Deaths))
+		output.WriteString(fmt.Sprintf("m_bConnected[%d] bool (true)\n", slot))
+		output.WriteString(fmt.Sprintf("m_iTeam[%d] integer (%d)\n", slot, player.Team))
+		output.WriteString(fmt.Sprintf("m_bAlive[%d] bool (%t)\n", slot, player.Alive))
+		output.WriteString(fmt.Sprintf("m_iHealth[%d] integer (%d)\n", slot, player.Health))
+		output.WriteString(fmt.Sprintf("m_iAccountID[%d] integer (%d)\n", slot, player.SteamID.AccountID))
+		output.WriteString(fmt.Sprintf("m_bValid[%d] bool (true)\n", slot))
+		output.WriteString(fmt.Sprintf("m_iUserID[%d] integer (%d)\n", slot, player.UserID))
+	}
+
+	return output.String()
+}
+
+func (s *Server) cvarList() string {
+	var output strings.Builder
+	output.WriteString("cvar list\n--------------\n")
+
+	for _, cvar := range s.opts.CVars {
+		value := cvar.Value
+		if cvar.Cmd {
+			value = "cmd"
+		}
+
+		var flags string
+		for _, flag := range cvar.Flags {
+			flags += fmt.Sprintf(`, "%s"`, flag)
+		}
+
+		output.WriteString(fmt.Sprintf("%-40s : %-8s : %-16s : %s\n", cvar.Name, value, flags, cvar.Description))
+	}
+
+	output.WriteString(fmt.Sprintf("--------------\n%d total convars/concommands\n", len(s.opts.CVars)))
+
+	return output.String()
+}
+
+func (s *Server) smPlugins() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[SM] Listing %d plugins:\n", len(s.opts.PluginsSM)))
+
+	for idx, plugin := range s.opts.PluginsSM {
+		output.WriteString(fmt.Sprintf("  %02d \"%s\" (%s) by %s\n", idx+1, plugin.Name, plugin.Version, plugin.Author))
+	}
+
+	return output.String()
+}
+
+func (s *Server) metaPlugins() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Listing %d plugins:\n", len(s.opts.PluginsMeta)))
+
+	for idx, plugin := range s.opts.PluginsMeta {
+		output.WriteString(fmt.Sprintf("  [%02d] %s (%s) by %s\n", idx+1, plugin.Name, plugin.Version, plugin.Author))
+	}
+
+	return output.String()
+}
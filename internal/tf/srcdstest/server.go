@@ -0,0 +1,420 @@
+// Package srcdstest provides an in-process fake srcds server for use in tests. It speaks the rcon protocol,
+// responds to the commands used by tf-tui with output matching the real game, and can emit log lines as udp
+// log packets to any registered logaddress.
+package srcdstest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+)
+
+const (
+	packetResponseValue int32 = 0
+	packetExecCommand   int32 = 2
+	packetAuthResponse  int32 = 2
+	packetAuth          int32 = 3
+	// chunkSize is the size the game splits responses at.
+	chunkSize = 4096
+	// logTimestampFormat is the format of the timestamp prefixing every log line.
+	logTimestampFormat = "01/02/2006 - 15:04:05"
+)
+
+var ErrPacketSize = errors.New("invalid rcon packet size")
+
+// Handler generates the response to a command, args contains everything after the command name.
+type Handler func(args string) string
+
+// Player is a player connected to the fake server.
+type Player struct {
+	UserID    int
+	Name      string
+	SteamID   steamid.SteamID
+	Team      tf.Team
+	Ping      int
+	Loss      int
+	Score     int
+	Deaths    int
+	Health    int
+	Alive     bool
+	Connected time.Duration
+	// Address is the ip:port of the player.
+	Address string
+}
+
+// Options configure the fake server.
+type Options struct {
+	Password string
+	// LogSecret is used as the sv_logsecret of the server. When set, log packets are sent using the 0x53
+	// packet type including the secret, otherwise the legacy 0x52 type is used.
+	LogSecret   int
+	Hostname    string
+	Map         string
+	Tags        []string
+	MaxPlayers  int
+	Stats       tf.Stats
+	CVars       tf.CVarList
+	PluginsSM   []tf.GamePlugin
+	PluginsMeta []tf.GamePlugin
+	// Handlers can be used to add responses to extra commands or override the built-in ones.
+	Handlers map[string]Handler
+}
+
+// Server is a fake srcds server listening for rcon connections on a random local port.
+type Server struct {
+	opts         Options
+	listener     net.Listener
+	mu           *sync.Mutex
+	players      []Player
+	logAddresses []string
+	commands     []string
+	conns        map[net.Conn]struct{}
+	waitGroup    *sync.WaitGroup
+}
+
+// NewServer starts a new fake server. Close must be called once finished with it.
+func NewServer(opts Options) (*Server, error) {
+	listener, errListen := net.Listen("tcp", "127.0.0.1:0")
+	if errListen != nil {
+		return nil, errListen
+	}
+
+	if opts.Hostname == "" {
+		opts.Hostname = "tf-tui test server"
+	}
+
+	if opts.Map == "" {
+		opts.Map = "pl_badwater"
+	}
+
+	if opts.MaxPlayers == 0 {
+		opts.MaxPlayers = 24
+	}
+
+	server := &Server{
+		opts:      opts,
+		listener:  listener,
+		mu:        &sync.Mutex{},
+		conns:     map[net.Conn]struct{}{},
+		waitGroup: &sync.WaitGroup{},
+	}
+
+	server.waitGroup.Go(server.serve)
+
+	return server, nil
+}
+
+// Address returns the rcon address of the server.
+func (s *Server) Address() string {
+	return s.listener.Addr().String()
+}
+
+// SetPlayers replaces the players currently connected to the server.
+func (s *Server) SetPlayers(players ...Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.players = slices.Clone(players)
+}
+
+// LogAddresses returns the addresses currently registered with logaddress_add.
+func (s *Server) LogAddresses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.logAddresses)
+}
+
+// Commands returns every command executed on the server, in the order they were received.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.commands)
+}
+
+// Log sends the line to all registered log addresses, prefixed with the current time like the game does.
+func (s *Server) Log(line string) error {
+	return s.LogAt(time.Now(), line)
+}
+
+// LogAt sends the line to all registered log addresses using the provided timestamp.
+func (s *Server) LogAt(timestamp time.Time, line string) error {
+	payload := bytes.NewBuffer([]byte{0xff, 0xff, 0xff, 0xff})
+	if s.opts.LogSecret > 0 {
+		payload.WriteByte(0x53)
+		payload.WriteString(fmt.Sprintf("%d", s.opts.LogSecret))
+	} else {
+		payload.WriteByte(0x52)
+	}
+
+	payload.WriteString(fmt.Sprintf("L %s: %s\n", timestamp.Format(logTimestampFormat), line))
+	payload.WriteByte(0)
+
+	var err error
+	for _, address := range s.LogAddresses() {
+		conn, errDial := net.Dial("udp", address)
+		if errDial != nil {
+			err = errors.Join(err, errDial)
+
+			continue
+		}
+
+		if _, errWrite := conn.Write(payload.Bytes()); errWrite != nil {
+			err = errors.Join(err, errWrite)
+		}
+
+		_ = conn.Close()
+	}
+
+	return err
+}
+
+// Close stops the server and disconnects all clients.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.waitGroup.Wait()
+
+	return err
+}
+
+func (s *Server) serve() {
+	for {
+		conn, errAccept := s.listener.Accept()
+		if errAccept != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.waitGroup.Go(func() {
+			s.handle(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		})
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		reader        = bufio.NewReader(conn)
+		authenticated bool
+	)
+
+	for {
+		reqID, kind, body, errRead := readPacket(reader)
+		if errRead != nil {
+			return
+		}
+
+		switch kind {
+		case packetAuth:
+			authID := reqID
+			authenticated = body == s.opts.Password
+			if !authenticated {
+				authID = -1
+			}
+
+			if err := writePackets(conn, reqID, packetResponseValue, ""); err != nil {
+				return
+			}
+
+			if err := writePackets(conn, authID, packetAuthResponse, ""); err != nil {
+				return
+			}
+		case packetExecCommand:
+			if !authenticated {
+				return
+			}
+
+			if err := writePackets(conn, reqID, packetResponseValue, s.exec(body)); err != nil {
+				return
+			}
+		case packetResponseValue:
+			// Mirror the empty response value back, followed by the same odd trailing packet as the game.
+			if err := writePackets(conn, reqID, packetResponseValue, ""); err != nil {
+				return
+			}
+
+			if err := writePackets(conn, reqID, packetResponseValue, "\x00\x01\x00\x00"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// exec runs each of the semicolon separated commands and returns their combined output.
+func (s *Server) exec(body string) string {
+	var output strings.Builder
+	for command := range strings.SplitSeq(body, ";") {
+		command = strings.TrimSpace(command)
+		if command == "" {
+			continue
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		name, args, _ := strings.Cut(command, " ")
+		args = strings.TrimSpace(args)
+
+		if handler, found := s.opts.Handlers[name]; found {
+			output.WriteString(handler(args))
+
+			continue
+		}
+
+		switch name {
+		case "status":
+			output.WriteString(s.status())
+		case "stats":
+			output.WriteString(s.stats())
+		case "g15_dumpplayer":
+			output.WriteString(s.dumpPlayer())
+		case "cvarlist":
+			output.WriteString(s.cvarList())
+		case "sm":
+			if args != "plugins list" {
+				output.WriteString("[SM] Usage: sm <command> [arguments]\n")
+
+				continue
+			}
+
+			output.WriteString(s.smPlugins())
+		case "meta":
+			if args != "list" {
+				output.WriteString("Usage: meta <command> [arguments]\n")
+
+				continue
+			}
+
+			output.WriteString(s.metaPlugins())
+		case "logaddress_add":
+			output.WriteString(s.logAddressAdd(args))
+		case "logaddress_del":
+			output.WriteString(s.logAddressDel(args))
+		case "logaddress_list":
+			output.WriteString(s.logAddressList())
+		default:
+			output.WriteString(fmt.Sprintf("Unknown command \"%s\"\n", name))
+		}
+	}
+
+	return output.String()
+}
+
+func (s *Server) logAddressAdd(address string) string {
+	if _, errResolve := net.ResolveUDPAddr("udp", address); errResolve != nil {
+		return fmt.Sprintf("logaddress_add:  unable to resolve %s\n", address)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.Contains(s.logAddresses, address) {
+		return fmt.Sprintf("logaddress_add:  %s is already in the list\n", address)
+	}
+
+	s.logAddresses = append(s.logAddresses, address)
+
+	return fmt.Sprintf("logaddress_add:  %s\n", address)
+}
+
+func (s *Server) logAddressDel(address string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.Index(s.logAddresses, address)
+	if idx == -1 {
+		return fmt.Sprintf("logaddress_del:  address %s not found in the list\n", address)
+	}
+
+	s.logAddresses = slices.Delete(s.logAddresses, idx, idx+1)
+
+	return fmt.Sprintf("logaddress_del:  %s\n", address)
+}
+
+func (s *Server) logAddressList() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.logAddresses) == 0 {
+		return "logaddress_list:  no addresses in the list\n"
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("logaddress_list: %d entries\n", len(s.logAddresses)))
+	for _, address := range s.logAddresses {
+		output.WriteString(address + "\n")
+	}
+
+	return output.String()
+}
+
+// writePackets writes the body to the connection, split over as many packets as required.
+func writePackets(writer io.Writer, reqID int32, kind int32, body string) error {
+	for {
+		chunk := body[:min(len(body), chunkSize)]
+		body = body[len(chunk):]
+
+		buffer := bytes.NewBuffer(make([]byte, 0, 14+len(chunk)))
+		_ = binary.Write(buffer, binary.LittleEndian, int32(10+len(chunk))) //nolint:gosec
+		_ = binary.Write(buffer, binary.LittleEndian, reqID)
+		_ = binary.Write(buffer, binary.LittleEndian, kind)
+		buffer.WriteString(chunk)
+		buffer.Write([]byte{0, 0})
+
+		if _, err := writer.Write(buffer.Bytes()); err != nil {
+			return err
+		}
+
+		if len(body) == 0 {
+			return nil
+		}
+	}
+}
+
+func readPacket(reader io.Reader) (int32, int32, string, error) {
+	var size int32
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return 0, 0, "", err
+	}
+
+	if size < 10 || size > chunkSize {
+		return 0, 0, "", fmt.Errorf("%w: %d", ErrPacketSize, size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return 0, 0, "", err
+	}
+
+	return int32(binary.LittleEndian.Uint32(data[0:4])), //nolint:gosec
+		int32(binary.LittleEndian.Uint32(data[4:8])), //nolint:gosec
+		string(data[8 : size-2]), nil
+}
@@ -7,6 +7,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/leighmacdonald/steamid/v4/extra"
 	"github.com/leighmacdonald/steamid/v4/steamid"
@@ -28,6 +29,87 @@ const (
 	RED
 )
 
+func (t Team) String() string {
+	switch t {
+	case SPEC:
+		return "SPEC"
+	case BLU:
+		return "BLU"
+	case RED:
+		return "RED"
+	default:
+		return ""
+	}
+}
+
+// ParseLogTeam converts the team names used in srcds logs into a Team.
+func ParseLogTeam(team string) Team {
+	switch strings.ToLower(team) {
+	case "red":
+		return RED
+	case "blue":
+		return BLU
+	case "spectator":
+		return SPEC
+	default:
+		return UNASSIGNED
+	}
+}
+
+type PlayerClass int
+
+const (
+	ClassUnknown PlayerClass = iota
+	Scout
+	Soldier
+	Pyro
+	Demo
+	Heavy
+	Engineer
+	Medic
+	Sniper
+	Spy
+)
+
+// ParseLogClass converts the class names used in srcds logs into a PlayerClass. This is the inverse of
+// PlayerClass.String.
+func ParseLogClass(class string) PlayerClass {
+	class = strings.ToLower(class)
+	for playerClass := Scout; playerClass <= Spy; playerClass++ {
+		if playerClass.String() == class {
+			return playerClass
+		}
+	}
+
+	return ClassUnknown
+}
+
+// String returns the class name as used in srcds logs.
+func (c PlayerClass) String() string {
+	switch c {
+	case Scout:
+		return "scout"
+	case Soldier:
+		return "soldier"
+	case Pyro:
+		return "pyro"
+	case Demo:
+		return "demoman"
+	case Heavy:
+		return "heavyweapons"
+	case Engineer:
+		return "engineer"
+	case Medic:
+		return "medic"
+	case Sniper:
+		return "sniper"
+	case Spy:
+		return "spy"
+	default:
+		return ""
+	}
+}
+
 type KickReason string
 
 const (
@@ -45,6 +127,71 @@ const (
 	ChatDestParty ChatDest = "party"
 )
 
+// chatCommands maps the chat destinations to the console command used to send to them.
+var chatCommands = map[ChatDest]string{ //nolint:gochecknoglobals
+	ChatDestAll:   "say",
+	ChatDestTeam:  "say_team",
+	ChatDestParty: "tf_party_chat",
+}
+
+// CommandValue removes the characters from text sent to the game console that would otherwise allow escaping a
+// quoted argument and running additional commands, such as a player named `"; quit; "`. This must be used for
+// all user or player controlled text included in a console command.
+func CommandValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '"', ';', '\n', '\r':
+			return -1
+		default:
+			return r
+		}
+	}, value)
+}
+
+// ChatCommands builds the console commands required to send the message to the chat destination. Messages
+// longer than MaxMessageLength are split into multiple messages. The message is cleaned using CommandValue.
+func ChatCommands(dest ChatDest, message string) []string {
+	command, found := chatCommands[dest]
+	if !found {
+		command = chatCommands[ChatDestAll]
+	}
+
+	parts := SplitMessage(CommandValue(message))
+	commands := make([]string, len(parts))
+	for idx, part := range parts {
+		commands[idx] = command + ` "` + part + `"`
+	}
+
+	return commands
+}
+
+// SplitMessage splits the message into chunks no longer than MaxMessageLength bytes. Splits happen on the last
+// space within the limit when possible, otherwise the message is split on the closest character boundary.
+func SplitMessage(message string) []string {
+	var parts []string
+
+	message = strings.TrimSpace(message)
+	for len(message) > MaxMessageLength {
+		cut := MaxMessageLength
+		for cut > 0 && !utf8.RuneStart(message[cut]) {
+			cut--
+		}
+
+		if space := strings.LastIndexByte(message[:cut], ' '); space > 0 {
+			cut = space
+		}
+
+		parts = append(parts, strings.TrimSpace(message[:cut]))
+		message = strings.TrimSpace(message[cut:])
+	}
+
+	if message != "" {
+		parts = append(parts, message)
+	}
+
+	return parts
+}
+
 // DumpPlayer holds the data returned from the `g15_dumpplayer` rcon command.
 type DumpPlayer struct {
 	Names     [MaxPlayerCount]string
@@ -80,6 +227,72 @@ type Status struct {
 	extra.Status
 	Stats  Stats
 	Region string
+	// Lobby is the matchmaking lobby of the server. This is only available in client mode.
+	Lobby Lobby
+}
+
+// LobbyMember is a player assigned to a matchmaking lobby.
+type LobbyMember struct {
+	SteamID steamid.SteamID
+	Team    Team
+	// Pending is true for players that have been assigned to the lobby, but have not yet joined it.
+	Pending bool
+}
+
+// Lobby is the matchmaking lobby for casual and competitive matches, as shown by the `tf_lobby_debug` command.
+type Lobby struct {
+	ID      string
+	Members []LobbyMember
+}
+
+// CTFLobbyShared: ID:00021f0d1d2a6d41  24 member(s), 1 pending
+//
+//	Member[0] [U:1:1234567]  team = TF_GC_TEAM_DEFENDERS  type = MATCH_PLAYER
+//	Pending[0] [U:1:7654321]  team = TF_GC_TEAM_INVADERS  type = MATCH_PLAYER
+var (
+	lobbyIDMatcher     = regexp.MustCompile(`CTFLobbyShared: ID:([0-9a-fA-F]+)`)                                        //nolint:gochecknoglobals
+	lobbyMemberMatcher = regexp.MustCompile(`^\s*(Member|Pending)\[\d+]\s+(\[U:\d:\d+])\s+team = (\w+)\s+type = (\w+)`) //nolint:gochecknoglobals
+)
+
+// ParseLobby transforms the output of the `tf_lobby_debug` command into a Lobby. An empty Lobby is
+// returned when not in a matchmaking lobby, such as on community servers.
+func ParseLobby(body string) Lobby {
+	var lobby Lobby
+	if match := lobbyIDMatcher.FindStringSubmatch(body); match != nil {
+		lobby.ID = match[1]
+	}
+
+	for line := range strings.Lines(body) {
+		match := lobbyMemberMatcher.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if match == nil {
+			continue
+		}
+
+		sid := steamid.New(match[2])
+		if !sid.Valid() {
+			continue
+		}
+
+		lobby.Members = append(lobby.Members, LobbyMember{
+			SteamID: sid,
+			Team:    parseLobbyTeam(match[3]),
+			Pending: match[1] == "Pending",
+		})
+	}
+
+	return lobby
+}
+
+// parseLobbyTeam converts the game coordinator team names into a Team. Defenders are always RED.
+func parseLobbyTeam(team string) Team {
+	switch team {
+	case "TF_GC_TEAM_DEFENDERS":
+		return RED
+	case "TF_GC_TEAM_INVADERS":
+		return BLU
+	default:
+		return UNASSIGNED
+	}
 }
 
 type GamePlugin struct {
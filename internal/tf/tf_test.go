@@ -1,8 +1,11 @@
 package tf_test
 
 import (
+	"strings"
 	"testing"
+	"unicode/utf8"
 
+	"github.com/leighmacdonald/steamid/v4/steamid"
 	"github.com/leighmacdonald/tf-tui/internal/tf"
 	"github.com/stretchr/testify/require"
 )
@@ -38,3 +41,57 @@ func TestParsePlugins(t *testing.T) {
 	require.Len(t, smPluginsFound, 4)
 
 }
+
+func TestChatCommands(t *testing.T) {
+	require.Equal(t, []string{`say "hello"`}, tf.ChatCommands(tf.ChatDestAll, "hello"))
+	require.Equal(t, []string{`say_team "hello quit"`}, tf.ChatCommands(tf.ChatDestTeam, "hello\"; quit"))
+	require.Equal(t, []string{`tf_party_chat "hi"`}, tf.ChatCommands(tf.ChatDestParty, " hi\n"))
+	require.Empty(t, tf.ChatCommands(tf.ChatDestAll, "  "))
+}
+
+func TestSplitMessage(t *testing.T) {
+	long := strings.Repeat("word ", 60)
+	parts := tf.SplitMessage(long)
+	require.Len(t, parts, 3)
+	require.Equal(t, strings.TrimSpace(long), strings.Join(parts, " "))
+
+	for _, part := range parts {
+		require.LessOrEqual(t, len(part), tf.MaxMessageLength)
+	}
+
+	unbroken := strings.Repeat("é", 100)
+	parts = tf.SplitMessage(unbroken)
+	require.Len(t, parts, 2)
+	require.Equal(t, unbroken, strings.Join(parts, ""))
+	require.True(t, utf8.ValidString(parts[0]))
+}
+
+func TestParseLobby(t *testing.T) {
+	const body = `CTFLobbyShared: ID:00021f0d1d2a6d41  2 member(s), 1 pending
+  Member[0] [U:1:1]  team = TF_GC_TEAM_DEFENDERS  type = MATCH_PLAYER
+  Member[1] [U:1:2]  team = TF_GC_TEAM_INVADERS  type = MATCH_PLAYER
+  Pending[0] [U:1:3]  team = TF_GC_TEAM_INVADERS  type = MATCH_PLAYER
+`
+
+	lobby := tf.ParseLobby(body)
+	require.Equal(t, "00021f0d1d2a6d41", lobby.ID)
+	require.Len(t, lobby.Members, 3)
+	require.Equal(t, tf.RED, lobby.Members[0].Team)
+	require.False(t, lobby.Members[0].Pending)
+	require.Equal(t, steamid.New("[U:1:2]"), lobby.Members[1].SteamID)
+	require.Equal(t, tf.BLU, lobby.Members[1].Team)
+	require.True(t, lobby.Members[2].Pending)
+
+	require.Empty(t, tf.ParseLobby("Failed to find lobby shared object\n").Members)
+}
+
+func TestParseLogClass(t *testing.T) {
+	for class := tf.Scout; class <= tf.Spy; class++ {
+		require.Equal(t, class, tf.ParseLogClass(class.String()))
+	}
+
+	require.Equal(t, tf.Heavy, tf.ParseLogClass("HeavyWeapons"))
+	require.Equal(t, tf.ClassUnknown, tf.ParseLogClass("heavy"))
+	require.Equal(t, tf.ClassUnknown, tf.ParseLogClass(""))
+	require.Empty(t, tf.ClassUnknown.String())
+}
@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -19,6 +23,8 @@ type ChatRow struct {
 	message   string
 	team      tf.Team
 	dead      bool
+	// ambiguous is set when multiple players share the name of the author, so the author cannot be known.
+	ambiguous bool
 }
 
 func (m ChatRow) View() string {
@@ -39,6 +45,10 @@ func (m ChatRow) View() string {
 		msg = styles.IconDead + " " + msg
 	}
 
+	if m.ambiguous {
+		msg = styles.IconWarning + " " + msg
+	}
+
 	return lipgloss.JoinHorizontal(lipgloss.Top,
 		styles.ChatTime.Render(m.createdOn.Format(time.TimeOnly)),
 		name,
@@ -54,8 +64,32 @@ const (
 	PartyChat
 )
 
-func newChatModel() chatModel {
-	return chatModel{}
+// Dest returns the game chat destination for the chat type.
+func (t ChatType) Dest() tf.ChatDest {
+	switch t {
+	case TeamChat:
+		return tf.ChatDestTeam
+	case PartyChat:
+		return tf.ChatDestParty
+	default:
+		return tf.ChatDestAll
+	}
+}
+
+func newChatModel(serverMode bool) chatModel {
+	input := textinput.New()
+	input.Placeholder = "Message..."
+
+	model := chatModel{
+		rows:         map[string][]ChatRow{},
+		rowsRendered: map[string]string{},
+		input:        input,
+		helpView:     help.New(),
+		serverMode:   serverMode,
+	}
+	model.input.Prompt = model.Placeholder() + " "
+
+	return model
 }
 
 type chatModel struct {
@@ -66,6 +100,10 @@ type chatModel struct {
 	rowsRendered    map[string]string
 	selectedsServer string
 	width           int
+	active          bool
+	serverMode      bool
+	input           textinput.Model
+	helpView        help.Model
 	inputOpen       bool
 	chatType        ChatType
 	incoming        chan events.Event
@@ -91,10 +129,18 @@ func (m chatModel) Init() tea.Cmd {
 
 func (m chatModel) Update(msg tea.Msg) (chatModel, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tabView:
+		m.active = msg == tabChat
+		if !m.active {
+			m.closeInput()
+		}
+	case tea.KeyMsg:
+		return m.onKey(msg)
 	case selectServerSnapshotMsg:
 		m.selectedsServer = msg.server.HostPort
 	case contentViewPortHeightMsg:
 		m.width = msg.width
+		m.input.Width = msg.width - lipgloss.Width(m.input.Prompt) - 2
 		if !m.ready {
 			m.viewport = viewport.New(msg.width, msg.contentViewPortHeight)
 			m.ready = true
@@ -113,24 +159,22 @@ func (m chatModel) Update(msg tea.Msg) (chatModel, tea.Cmd) {
 			break
 		}
 
-		team := tf.UNASSIGNED
-		for _, player := range m.players {
-			if player.SteamID.Equal(evt.PlayerSID) {
-				team = player.Team
-
-				break
-			}
-		}
-
 		row := ChatRow{
 			steamID:   evt.PlayerSID,
 			name:      evt.Player,
 			createdOn: msg.Timestamp,
 			message:   evt.Message,
-			team:      team,
+			team:      tf.UNASSIGNED,
 			dead:      evt.Dead,
 		}
 
+		if matches := m.players.AllByName(evt.Player); len(matches) == 1 {
+			row.steamID = matches[0].SteamID
+			row.team = matches[0].Team
+		} else if len(matches) > 1 {
+			row.ambiguous = true
+		}
+
 		if _, ok := m.rows[msg.HostPort]; !ok {
 			m.rows[msg.HostPort] = []ChatRow{}
 		}
@@ -146,11 +190,77 @@ func (m chatModel) Update(msg tea.Msg) (chatModel, tea.Cmd) {
 	return m, cmd
 }
 
+func (m chatModel) onKey(msg tea.KeyMsg) (chatModel, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+
+	if !m.inputOpen {
+		if key.Matches(msg, defaultKeyMap.chatInput) {
+			m.inputOpen = true
+
+			return m, m.input.Focus()
+		}
+
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, defaultKeyMap.back):
+		m.closeInput()
+
+		return m, nil
+	case key.Matches(msg, defaultKeyMap.chatType):
+		m.nextChatType()
+
+		return m, nil
+	case key.Matches(msg, defaultKeyMap.chatInput):
+		commands := tf.ChatCommands(m.chatType.Dest(), m.input.Value())
+		m.input.SetValue("")
+		if len(commands) == 0 || m.selectedsServer == "" {
+			return m, nil
+		}
+
+		// Sent as a single command so the parts of split messages cannot arrive out of order.
+		return m, sendRCONCommand(m.selectedsServer, strings.Join(commands, ";"))
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+
+	return m, cmd
+}
+
+// nextChatType cycles through the available chat types. Party chat is only available to a game client.
+func (m *chatModel) nextChatType() {
+	m.chatType = (m.chatType + 1) % (PartyChat + 1)
+	if m.serverMode && m.chatType == PartyChat {
+		m.chatType = AllChat
+	}
+
+	m.input.Prompt = m.Placeholder() + " "
+}
+
+func (m *chatModel) closeInput() {
+	m.inputOpen = false
+	m.input.SetValue("")
+	m.input.Blur()
+}
+
 func (m chatModel) View(height int) string {
 	titleBar := renderTitleBar(m.width, "Game Chat")
-	m.viewport.Height = height - lipgloss.Height(titleBar)
+
+	var footer string
+	if m.inputOpen {
+		footer = lipgloss.JoinHorizontal(lipgloss.Top, m.input.View(), " ",
+			m.helpView.ShortHelpView([]key.Binding{defaultKeyMap.chatInput, defaultKeyMap.chatType, defaultKeyMap.back}))
+	} else {
+		footer = m.helpView.ShortHelpView([]key.Binding{defaultKeyMap.chatInput})
+	}
+
+	m.viewport.Height = height - lipgloss.Height(titleBar) - lipgloss.Height(footer)
 	rows := m.rowsRendered[m.selectedsServer]
 	m.viewport.SetContent(rows)
 
-	return lipgloss.JoinVertical(lipgloss.Left, titleBar, m.viewport.View())
+	return lipgloss.JoinVertical(lipgloss.Left, titleBar, m.viewport.View(), footer)
 }
@@ -20,6 +20,8 @@ type keymap struct {
 	quit          key.Binding
 	config        key.Binding
 	chat          key.Binding
+	chatInput     key.Binding
+	chatType      key.Binding
 	up            key.Binding
 	down          key.Binding
 	left          key.Binding
@@ -33,10 +35,21 @@ type keymap struct {
 	bd            key.Binding
 	comp          key.Binding
 	notes         key.Binding
+	save          key.Binding
+	mark          key.Binding
+	voteKick      key.Binding
+	toggle        key.Binding
+	exportMarks   key.Binding
 	console       key.Binding
+	history       key.Binding
+	kills         key.Binding
 	help          key.Binding
 	consoleInput  key.Binding
 	consoleCancel key.Binding
+	replayPause   key.Binding
+	replayBack    key.Binding
+	replayForward key.Binding
+	replaySpeed   key.Binding
 }
 
 // TODO make configurable.
@@ -115,6 +128,26 @@ var defaultKeyMap = keymap{
 		key.WithKeys("n"),
 		key.WithHelp("n", "Notes"),
 	),
+	mark: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "Mark"),
+	),
+	voteKick: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "Vote Kick"),
+	),
+	exportMarks: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "Export Marks"),
+	),
+	toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "Toggle"),
+	),
+	save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "Save"),
+	),
 	console: key.NewBinding(
 		key.WithKeys("`"),
 		key.WithHelp("`", "Console"),
@@ -123,6 +156,38 @@ var defaultKeyMap = keymap{
 		key.WithKeys("t"),
 		key.WithHelp("t", "Chat"),
 	),
+	chatInput: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "Send Chat"),
+	),
+	chatType: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "Chat Type"),
+	),
+	kills: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "Kill Feed"),
+	),
+	history: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "History"),
+	),
+	replayPause: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "Pause Replay"),
+	),
+	replayBack: key.NewBinding(
+		key.WithKeys(","),
+		key.WithHelp(",", "Seek Back"),
+	),
+	replayForward: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "Seek Forward"),
+	),
+	replaySpeed: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "Replay Speed"),
+	),
 }
 
 type configIdx int
@@ -32,12 +32,14 @@ func (r LogRow) Render(width int) string {
 	switch r.EventType {
 	case events.Msg:
 		body = styles.ConsoleMsg.Render(body)
-	case events.Connect:
+	case events.Connect, events.Entered:
 		body = styles.ConsoleConnect.Render(body)
-	case events.Disconnect:
+	case events.Disconnect, events.PlayerDisconnect:
 		body = styles.ConsoleDisconnect.Render(body)
 	case events.Address:
 		body = styles.ConsoleAddress.Render(body)
+	case events.Lobby:
+		body = styles.ConsoleLobby.Render(body)
 	case events.Hostname:
 		body = styles.ConsoleHostname.Render(body)
 	case events.StatusID:
@@ -76,6 +78,7 @@ type consoleModel struct {
 	input          textinput.Model
 	inputActive    bool
 	inputZoneID    string
+	subscribers    []SubscriberStats
 }
 
 func newConsoleModel() *consoleModel {
@@ -146,6 +149,8 @@ func (m *consoleModel) Update(msg tea.Msg) (*consoleModel, tea.Cmd) {
 		return m.onLogs(msg), tea.Batch(cmds...)
 	case serverCVarList:
 		m.cvarList[msg.HostPort] = msg.List
+	case RouterStats:
+		m.subscribers = msg.Subscribers
 	}
 
 	return m, tea.Batch(cmds...)
@@ -165,7 +170,8 @@ func (m *consoleModel) onLogs(event events.Event) *consoleModel {
 
 	if m.filterNoisy {
 		valid := true
-		for _, prefix := range []string{"# ", "version ", "steamid ", "players ", "map ", "account ", "edicts "} {
+		for _, prefix := range []string{"# ", "version ", "steamid ", "players ", "map ", "account ", "edicts ",
+			"CTFLobbyShared", "  Member[", "  Pending["} {
 			if strings.HasPrefix(parts[1], prefix) {
 				valid = false
 
@@ -177,7 +183,7 @@ func (m *consoleModel) onLogs(event events.Event) *consoleModel {
 		}
 	}
 
-	newRow := LogRow{Content: safeString(parts[1]), CreatedOn: time.Now(), EventType: event.Type}
+	newRow := LogRow{Content: safeString(parts[1]), CreatedOn: event.Timestamp, EventType: event.Type}
 	m.rowsMu.Lock()
 	// This does not use JoinVertical currently as it takes more and more CPU as time goes on
 	// and the console log fills becoming unusable.
@@ -209,9 +215,10 @@ func (m *consoleModel) Render(height int) string {
 		title = renderTitleBar(m.width, fmt.Sprintf("Console Log: %d Messages", m.rowsCount[m.selectedServer.HostPort]))
 	}
 
+	subscribers := m.renderSubscribers()
 	input := zone.Mark(m.inputZoneID, m.input.View())
 
-	m.viewPort.Height = height - lipgloss.Height(title) - lipgloss.Height(input)
+	m.viewPort.Height = height - lipgloss.Height(title) - lipgloss.Height(subscribers) - lipgloss.Height(input)
 	wasBottom := m.viewPort.AtBottom()
 
 	m.viewPort.SetContent(content)
@@ -219,5 +226,25 @@ func (m *consoleModel) Render(height int) string {
 		m.viewPort.GotoBottom()
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, title, m.viewPort.View(), input)
+	return lipgloss.JoinVertical(lipgloss.Left, title, subscribers, m.viewPort.View(), input)
+}
+
+// renderSubscribers renders the delivered and dropped event counts of the router subscribers receiving events
+// for the selected server.
+func (m *consoleModel) renderSubscribers() string {
+	var counts []string
+	for _, subscriber := range m.subscribers {
+		if subscriber.HostPort != "" && subscriber.HostPort != m.selectedServer.HostPort {
+			continue
+		}
+
+		counts = append(counts, fmt.Sprintf("%s (%s): %d/%d", subscriber.Name, subscriber.Policy,
+			subscriber.Delivered, subscriber.Dropped))
+	}
+
+	if len(counts) == 0 {
+		return ""
+	}
+
+	return renderTitleBar(m.width, "Events delivered/dropped  "+strings.Join(counts, "  "))
 }
@@ -61,6 +61,10 @@ func (m helpModel) View() string {
 			defaultKeyMap.quit,
 			defaultKeyMap.help,
 			defaultKeyMap.accept,
+			defaultKeyMap.notes,
+			defaultKeyMap.mark,
+			defaultKeyMap.voteKick,
+			defaultKeyMap.exportMarks,
 		},
 	})
 
@@ -71,6 +75,8 @@ func (m helpModel) View() string {
 			defaultKeyMap.bd,
 			defaultKeyMap.comp,
 			defaultKeyMap.chat,
+			defaultKeyMap.kills,
+			defaultKeyMap.history,
 			defaultKeyMap.console,
 		},
 	})
@@ -82,6 +88,10 @@ func (m helpModel) View() string {
 			defaultKeyMap.down,
 			defaultKeyMap.left,
 			defaultKeyMap.right,
+			defaultKeyMap.replayPause,
+			defaultKeyMap.replayBack,
+			defaultKeyMap.replayForward,
+			defaultKeyMap.replaySpeed,
 		},
 	})
 
@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/ui/styles"
+)
+
+// MatchSummary is a brief overview of a previously played match.
+type MatchSummary struct {
+	MatchID   int64
+	Hostname  string
+	Address   string
+	MapName   string
+	Duration  time.Duration
+	Players   int
+	CreatedOn time.Time
+}
+
+// MatchHistory is the list of previously played matches, newest first.
+type MatchHistory []MatchSummary
+
+// MatchPlayer is the final scoreboard entry of a player in a previous match.
+type MatchPlayer struct {
+	SteamID   steamid.SteamID
+	Name      string
+	Score     int
+	Deaths    int
+	Ping      int
+	Connected time.Duration
+}
+
+// MatchChat is a single chat message that was sent during a previous match.
+type MatchChat struct {
+	SteamID   steamid.SteamID
+	Name      string
+	Message   string
+	TeamOnly  bool
+	CreatedOn time.Time
+}
+
+// MatchDetail contains the full scoreboard and chat log of a previous match.
+type MatchDetail struct {
+	MatchID int64
+	Players []MatchPlayer
+	Chat    []MatchChat
+}
+
+type historyTableCol int
+
+const (
+	colHistoryDate historyTableCol = iota
+	colHistoryMap
+	colHistoryServer
+	colHistoryDuration
+	colHistoryPlayers
+)
+
+type historyTableSize = int
+
+const (
+	colHistoryDateSize     historyTableSize = 21
+	colHistoryMapSize      historyTableSize = 24
+	colHistoryDurationSize historyTableSize = 10
+	colHistoryPlayersSize  historyTableSize = 8
+)
+
+func newHistoryModel() historyModel {
+	return historyModel{}
+}
+
+// historyModel lists previously recorded matches and allows drilling down into the scoreboard
+// and chat log of a single match.
+type historyModel struct {
+	matches    MatchHistory
+	detail     MatchDetail
+	selected   int
+	showDetail bool
+	active     bool
+	width      int
+}
+
+func (m historyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m historyModel) Update(msg tea.Msg) (historyModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case contentViewPortHeightMsg:
+		m.width = msg.width
+	case tabView:
+		m.active = msg == tabHistory
+		if m.active {
+			// Always refresh as new matches may have been recorded since the last visit.
+			return m, requestMatchHistory()
+		}
+	case MatchHistory:
+		m.matches = msg
+		m.selected = max(0, min(m.selected, len(m.matches)-1))
+	case MatchDetail:
+		m.detail = msg
+		m.showDetail = true
+	case tea.KeyMsg:
+		if !m.active {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, defaultKeyMap.up):
+			if !m.showDetail && m.selected > 0 {
+				m.selected--
+			}
+		case key.Matches(msg, defaultKeyMap.down):
+			if !m.showDetail && m.selected < len(m.matches)-1 {
+				m.selected++
+			}
+		case key.Matches(msg, defaultKeyMap.accept):
+			if !m.showDetail && len(m.matches) > 0 {
+				return m, requestMatchDetail(m.matches[m.selected].MatchID)
+			}
+		case key.Matches(msg, defaultKeyMap.back):
+			m.showDetail = false
+		}
+	}
+
+	return m, nil
+}
+
+func (m historyModel) Render(height int) string {
+	if m.showDetail {
+		return m.renderDetail(height)
+	}
+
+	titleBar := renderTitleBar(m.width, "Match History")
+	if len(m.matches) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, titleBar,
+			styles.InfoMessage.Width(m.width).Render("No matches recorded "+styles.IconHistory))
+	}
+
+	// Only render the window of rows that fit, keeping the selected row visible.
+	visible := max(1, height-lipgloss.Height(titleBar)-1)
+	offset := max(0, m.selected-visible+1)
+	end := min(len(m.matches), offset+visible)
+
+	var rows [][]string
+	for _, match := range m.matches[offset:end] {
+		server := match.Hostname
+		if server == "" {
+			server = match.Address
+		}
+
+		rows = append(rows, []string{
+			match.CreatedOn.Format(time.DateTime),
+			match.MapName,
+			server,
+			match.Duration.String(),
+			strconv.Itoa(match.Players),
+		})
+	}
+
+	content := newUnstyledTable("Date", "Map", "Server", "Duration", "Players").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			var width int
+			switch historyTableCol(col) {
+			case colHistoryDate:
+				width = colHistoryDateSize
+			case colHistoryMap:
+				width = colHistoryMapSize
+			case colHistoryServer:
+				width = m.width - colHistoryDateSize - colHistoryMapSize - colHistoryDurationSize - colHistoryPlayersSize - 2
+			case colHistoryDuration:
+				width = colHistoryDurationSize
+			case colHistoryPlayers:
+				width = colHistoryPlayersSize
+			}
+			switch {
+			case row == table.HeaderRow:
+				return styles.BanTableHeading.Width(width)
+			case row+offset == m.selected:
+				return styles.SelectedCellStyleBlu.Width(width)
+			case row%2 == 0:
+				return styles.TableRowValuesEven.Width(width)
+			default:
+				return styles.TableRowValuesOdd.Width(width)
+			}
+		}).Render()
+
+	return lipgloss.JoinVertical(lipgloss.Left, titleBar, content)
+}
+
+func (m historyModel) renderDetail(height int) string {
+	var summary MatchSummary
+	for _, match := range m.matches {
+		if match.MatchID == m.detail.MatchID {
+			summary = match
+
+			break
+		}
+	}
+
+	titleBar := renderTitleBar(m.width, fmt.Sprintf("%s @ %s (%s)",
+		summary.MapName, summary.Hostname, summary.CreatedOn.Format(time.DateTime)))
+	halfWidth := m.width / 2
+
+	var players [][]string
+	for _, player := range m.detail.Players {
+		players = append(players, []string{
+			player.Name,
+			strconv.Itoa(player.Score),
+			strconv.Itoa(player.Deaths),
+			strconv.Itoa(player.Ping),
+			player.Connected.String(),
+		})
+	}
+
+	scoreboard := newUnstyledTable("Name", "Score", "Deaths", "Ping", "Time").
+		Rows(players...).
+		Height(height - lipgloss.Height(titleBar)).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			width := 8
+			if col == 0 {
+				width = halfWidth - 4*width - 2
+			}
+			switch {
+			case row == table.HeaderRow:
+				return styles.BanTableHeading.Width(width)
+			case row%2 == 0:
+				return styles.TableRowValuesEven.Width(width)
+			default:
+				return styles.TableRowValuesOdd.Width(width)
+			}
+		}).Render()
+
+	chatRows := make([]string, len(m.detail.Chat))
+	for idx, msg := range m.detail.Chat {
+		message := msg.Message
+		if msg.TeamOnly {
+			message = "(TEAM) " + message
+		}
+
+		chatRows[idx] = lipgloss.JoinHorizontal(lipgloss.Top,
+			styles.ChatTime.Render(msg.CreatedOn.Format(time.TimeOnly)),
+			styles.ChatNameOther.Render(msg.Name),
+			styles.ChatMessage.Render(message))
+	}
+
+	// Show the most recent messages when the log does not fit.
+	if overflow := len(chatRows) - (height - lipgloss.Height(titleBar)); overflow > 0 {
+		chatRows = chatRows[overflow:]
+	}
+
+	chatLog := lipgloss.NewStyle().Width(m.width - halfWidth - 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, chatRows...))
+
+	return lipgloss.JoinVertical(lipgloss.Left, titleBar,
+		lipgloss.JoinHorizontal(lipgloss.Top, scoreboard, chatLog))
+}
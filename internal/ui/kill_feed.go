@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/leighmacdonald/tf-tui/internal/config"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/events"
+	"github.com/leighmacdonald/tf-tui/internal/ui/styles"
+)
+
+// maxKillRows is the maximum number of kill feed rows retained per server.
+const maxKillRows = 250
+
+type KillRow struct {
+	createdOn  time.Time
+	killer     string
+	killerTeam tf.Team
+	victim     string
+	victimTeam tf.Team
+	weapon     string
+	crit       bool
+	self       bool
+}
+
+func (r KillRow) View() string {
+	weapon := r.weapon
+	if r.crit {
+		weapon += " (crit)"
+	}
+
+	weaponStyle := styles.ChatMessage
+	if r.self {
+		weaponStyle = styles.PlayerTableRowMark
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		styles.ChatTime.Render(r.createdOn.Format(time.TimeOnly)),
+		teamNameStyle(r.killerTeam).Render(r.killer),
+		weaponStyle.Render(" "+styles.IconKill+" "+weapon+" "),
+		teamNameStyle(r.victimTeam).Render(r.victim),
+	)
+}
+
+func teamNameStyle(team tf.Team) lipgloss.Style {
+	switch team {
+	case tf.RED:
+		return styles.ChatNameRed
+	case tf.BLU:
+		return styles.ChatNameBlu
+	default:
+		return styles.ChatNameOther
+	}
+}
+
+func newKillFeedModel() killFeedModel {
+	return killFeedModel{rows: map[string][]KillRow{}, players: map[string]Players{}}
+}
+
+// killFeedModel shows a live feed of all kills for the selected server.
+type killFeedModel struct {
+	// players are the players of every server, as kills are recorded for all servers and not only the selected one.
+	players        map[string]Players
+	selfSID        steamid.SteamID
+	rows           map[string][]KillRow
+	kills          int
+	deaths         int
+	selectedServer string
+	viewport       viewport.Model
+	ready          bool
+	width          int
+}
+
+func (m killFeedModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m killFeedModel) Update(msg tea.Msg) (killFeedModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case config.Config:
+		m.selfSID = msg.SteamID
+	case selectServerSnapshotMsg:
+		m.selectedServer = msg.server.HostPort
+	case []Snapshot:
+		for _, snapshot := range msg {
+			m.players[snapshot.HostPort] = snapshot.Server.Players
+		}
+	case contentViewPortHeightMsg:
+		m.width = msg.width
+		if !m.ready {
+			m.viewport = viewport.New(msg.width, msg.contentViewPortHeight)
+			m.ready = true
+		} else {
+			m.viewport.Height = msg.contentViewPortHeight
+		}
+	case events.Event:
+		evt, ok := msg.Data.(events.KillEvent)
+		if !ok {
+			break
+		}
+
+		m.onKill(msg.HostPort, msg.Timestamp, evt)
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+
+	return m, cmd
+}
+
+func (m *killFeedModel) onKill(hostPort string, timeStamp time.Time, evt events.KillEvent) {
+	killer := killPlayer(m.players[hostPort], evt.PlayerSID, evt.Player)
+	victim := killPlayer(m.players[hostPort], evt.VictimSID, evt.Victim)
+
+	row := KillRow{
+		createdOn:  timeStamp,
+		killer:     evt.Player,
+		killerTeam: killTeam(evt.PlayerTeam, killer),
+		victim:     evt.Victim,
+		victimTeam: killTeam(evt.VictimTeam, victim),
+		weapon:     evt.Weapon,
+		crit:       evt.Crit,
+	}
+
+	if m.selfSID.Valid() && !killer.SteamID.Equal(victim.SteamID) {
+		switch {
+		case killer.SteamID.Equal(m.selfSID):
+			m.kills++
+			row.self = true
+		case victim.SteamID.Equal(m.selfSID):
+			m.deaths++
+			row.self = true
+		}
+	}
+
+	rows := append(m.rows[hostPort], row)
+	if len(rows) > maxKillRows {
+		rows = rows[len(rows)-maxKillRows:]
+	}
+
+	m.rows[hostPort] = rows
+}
+
+// killPlayer finds the player involved in a kill, preferring the steam id included with server mode kills. Client
+// mode kills only include the name, which is left unresolved when it is shared by multiple players.
+func killPlayer(players Players, steamID steamid.SteamID, name string) Player {
+	if steamID.Valid() {
+		if player, found := players.BySteamID(steamID); found {
+			return player
+		}
+
+		return Player{SteamID: steamID, Name: name}
+	}
+
+	player, _ := players.ByName(name)
+
+	return player
+}
+
+// killTeam returns the team included with server mode kills, falling back to the last known team of the player.
+func killTeam(team tf.Team, player Player) tf.Team {
+	if team != tf.UNASSIGNED {
+		return team
+	}
+
+	return player.Team
+}
+
+func (m killFeedModel) View(height int) string {
+	titleBar := renderTitleBar(m.width, fmt.Sprintf("Kill Feed (Session K/D: %d/%d)", m.kills, m.deaths))
+
+	rows := m.rows[m.selectedServer]
+	rendered := make([]string, len(rows))
+	for idx, row := range rows {
+		rendered[idx] = row.View()
+	}
+
+	m.viewport.Height = height - lipgloss.Height(titleBar)
+	m.viewport.SetContent(strings.Join(rendered, "\n"))
+	m.viewport.GotoBottom()
+
+	return lipgloss.JoinVertical(lipgloss.Left, titleBar, m.viewport.View())
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/leighmacdonald/tf-tui/internal/ui/styles"
+)
+
+// markTags are the tags that can be applied to a player.
+var markTags = []string{"cheater", "bot", "racist", "harasser", "exploiter", "suspicious", "friend"} //nolint:gochecknoglobals
+
+// markModel allows tagging the currently selected player. Saving without any selected tags removes the mark.
+type markModel struct {
+	player   Player
+	tags     []string
+	reason   textinput.Model
+	helpView help.Model
+	// cursor is the index of the focused tag, with len(markTags) being the reason input.
+	cursor int
+	active bool
+	width  int
+}
+
+func newMarkModel() markModel {
+	reason := textinput.New()
+	reason.Placeholder = "Reason..."
+	reason.CharLimit = 200
+
+	return markModel{reason: reason, helpView: help.New()}
+}
+
+func (m markModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m markModel) Update(msg tea.Msg) (markModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case contentViewPortHeightMsg:
+		m.width = msg.width
+		m.reason.Width = msg.width - 12
+	case contentView:
+		m.active = msg == viewMark
+		if m.active {
+			m.cursor = 0
+			m.tags = slices.Clone(m.player.MarkTags)
+			m.reason.SetValue(m.player.MarkReason)
+		}
+		m.reason.Blur()
+	case selectedPlayerMsg:
+		if !m.active {
+			m.player = msg.player
+		}
+	case tea.KeyMsg:
+		if !m.active {
+			break
+		}
+
+		return m.onKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m markModel) onKey(msg tea.KeyMsg) (markModel, tea.Cmd) {
+	inputFocused := m.cursor == len(markTags)
+
+	switch {
+	case key.Matches(msg, defaultKeyMap.back):
+		return m, setContentView(viewMain)
+	case key.Matches(msg, defaultKeyMap.accept):
+		if !m.player.SteamID.Valid() {
+			return m, setStatusMessage("No player selected", true)
+		}
+
+		m.player.MarkTags = m.tags
+		m.player.MarkReason = m.reason.Value()
+
+		status := "Marked " + m.player.Name
+		if len(m.tags) == 0 {
+			status = "Removed mark from " + m.player.Name
+		}
+
+		return m, tea.Batch(
+			markPlayer(m.player.SteamID, m.player.MarkTags, m.player.MarkReason),
+			selectPlayer(m.player),
+			setStatusMessage(status, false),
+			setContentView(viewMain))
+	case key.Matches(msg, defaultKeyMap.nextTab):
+		return m, m.moveCursor(1)
+	case key.Matches(msg, defaultKeyMap.prevTab):
+		return m, m.moveCursor(-1)
+	case inputFocused:
+		// Everything else is considered text input.
+		var cmd tea.Cmd
+		m.reason, cmd = m.reason.Update(msg)
+
+		return m, cmd
+	case key.Matches(msg, defaultKeyMap.up):
+		return m, m.moveCursor(-1)
+	case key.Matches(msg, defaultKeyMap.down):
+		return m, m.moveCursor(1)
+	case key.Matches(msg, defaultKeyMap.toggle):
+		tag := markTags[m.cursor]
+		if idx := slices.Index(m.tags, tag); idx >= 0 {
+			m.tags = slices.Delete(m.tags, idx, idx+1)
+		} else {
+			m.tags = append(m.tags, tag)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *markModel) moveCursor(offset int) tea.Cmd {
+	m.cursor = max(0, min(len(markTags), m.cursor+offset))
+	if m.cursor == len(markTags) {
+		return m.reason.Focus()
+	}
+
+	m.reason.Blur()
+
+	return nil
+}
+
+func (m markModel) View(height int) string {
+	title := renderTitleBar(m.width, "Mark Player: "+m.player.Name)
+
+	rows := []string{title, ""}
+	for idx, tag := range markTags {
+		check := "[ ] "
+		if slices.Contains(m.tags, tag) {
+			check = "[x] "
+		}
+
+		style := styles.BlurredStyle
+		if idx == m.cursor {
+			style = styles.FocusedStyle
+		}
+
+		rows = append(rows, style.Render(check+tag))
+	}
+
+	rows = append(rows, "", styles.HelpStyle.Render("Reason: ")+m.reason.View(), "",
+		m.helpView.ShortHelpView([]key.Binding{
+			defaultKeyMap.toggle, defaultKeyMap.nextTab, defaultKeyMap.accept, defaultKeyMap.back,
+		}))
+
+	return lipgloss.NewStyle().Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
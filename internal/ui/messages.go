@@ -4,6 +4,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/leighmacdonald/steamid/v4/steamid"
 	"github.com/leighmacdonald/tf-tui/internal/config"
 	"github.com/leighmacdonald/tf-tui/internal/tf"
 )
@@ -31,7 +32,7 @@ type sortPlayersMsg struct {
 
 func selectPlayer(player Player) func() tea.Msg {
 	return func() tea.Msg {
-		return selectedPlayerMsg{player: player}
+		return selectedPlayerMsg{player: player, notes: player.Notes}
 	}
 }
 
@@ -54,14 +55,22 @@ func clearErrorAfter(t time.Duration) tea.Cmd {
 	})
 }
 
-type statusMsg struct {
+// StatusMsg shows a temporary message in the status bar.
+type StatusMsg struct {
 	Message string
 	Err     bool
 }
 
+// AlertMsg shows a triggered alert in the status bar and/or rings the terminal bell. Nothing is shown when
+// the message is empty.
+type AlertMsg struct {
+	Message string
+	Bell    bool
+}
+
 func setStatusMessage(msg string, err bool) tea.Cmd {
 	return func() tea.Msg {
-		return statusMsg{Message: msg, Err: err}
+		return StatusMsg{Message: msg, Err: err}
 	}
 }
 
@@ -130,3 +139,103 @@ type RCONCommand struct {
 func sendRCONCommand(hostPort string, command string) tea.Cmd {
 	return func() tea.Msg { return RCONCommand{HostPort: hostPort, Command: command} }
 }
+
+// SaveNotesRequest asks the parent app to store the notes for a player.
+type SaveNotesRequest struct {
+	SteamID steamid.SteamID
+	Notes   string
+}
+
+func saveNotes(steamID steamid.SteamID, notes string) tea.Cmd {
+	return func() tea.Msg { return SaveNotesRequest{SteamID: steamID, Notes: notes} }
+}
+
+// MarkPlayerRequest asks the parent app to tag a player. An empty set of tags removes the mark.
+type MarkPlayerRequest struct {
+	SteamID steamid.SteamID
+	Tags    []string
+	Reason  string
+}
+
+func markPlayer(steamID steamid.SteamID, tags []string, reason string) tea.Cmd {
+	return func() tea.Msg { return MarkPlayerRequest{SteamID: steamID, Tags: tags, Reason: reason} }
+}
+
+// VoteKickRequest asks the parent app to call a vote to kick a player from the current server.
+type VoteKickRequest struct {
+	SteamID steamid.SteamID
+	UserID  int
+	Reason  tf.KickReason
+}
+
+func voteKick(steamID steamid.SteamID, userID int, reason tf.KickReason) tea.Cmd {
+	return func() tea.Msg { return VoteKickRequest{SteamID: steamID, UserID: userID, Reason: reason} }
+}
+
+// ExportMarksRequest asks the parent app to export all marked players as a bot detector playerlist.
+type ExportMarksRequest struct{}
+
+func exportMarks() tea.Cmd {
+	return func() tea.Msg { return ExportMarksRequest{} }
+}
+
+// MatchHistoryRequest asks the parent app to load the list of recorded matches.
+type MatchHistoryRequest struct{}
+
+func requestMatchHistory() tea.Cmd {
+	return func() tea.Msg { return MatchHistoryRequest{} }
+}
+
+// MatchDetailRequest asks the parent app to load the scoreboard and chat log of a recorded match.
+type MatchDetailRequest struct {
+	MatchID int64
+}
+
+func requestMatchDetail(matchID int64) tea.Cmd {
+	return func() tea.Msg { return MatchDetailRequest{MatchID: matchID} }
+}
+
+// ReplayAction is a playback control used while replaying saved logs.
+type ReplayAction int
+
+const (
+	ReplayTogglePause ReplayAction = iota
+	ReplaySeekBack
+	ReplaySeekForward
+	ReplayNextSpeed
+)
+
+// ReplayRequest asks the parent app to control the playback of replayed logs.
+type ReplayRequest struct {
+	Action ReplayAction
+}
+
+func controlReplay(action ReplayAction) tea.Cmd {
+	return func() tea.Msg { return ReplayRequest{Action: action} }
+}
+
+// ReplayStatus is the playback state of replayed logs. This is only sent while replaying.
+type ReplayStatus struct {
+	// Position is the original time of the next line to be replayed.
+	Position time.Time
+	End      time.Time
+	// Speed is the playback speed multiplier, 0 is as fast as possible.
+	Speed  float64
+	Paused bool
+	Done   bool
+}
+
+// SubscriberStats are the delivery counters of a single event router subscriber.
+type SubscriberStats struct {
+	Name      string
+	HostPort  string
+	Policy    string
+	Delivered int64
+	Dropped   int64
+}
+
+// RouterStats are the delivery counters of all event router subscribers, shown in the console tab to help
+// find events that are being dropped by slow subscribers.
+type RouterStats struct {
+	Subscribers []SubscriberStats
+}
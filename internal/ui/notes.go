@@ -1,51 +1,90 @@
 package ui
 
 import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// notesModel is a simple editor for the notes of the currently selected player.
 type notesModel struct {
-	viewPort viewport.Model
 	textarea textarea.Model
+	helpView help.Model
 	player   Player
+	active   bool
 	width    int
 }
 
 func newNotesModel() notesModel {
 	textArea := textarea.New()
-	// textArea.SetHeight(10)
-	textArea.SetValue("A note...")
-	viewPort := viewport.New(10, 10)
+	textArea.Placeholder = "Notes about this player..."
+	textArea.ShowLineNumbers = false
+	textArea.CharLimit = 0
 
-	return notesModel{textarea: textArea, viewPort: viewPort}
+	return notesModel{textarea: textArea, helpView: help.New()}
 }
 
 func (m notesModel) Init() tea.Cmd {
-	return tea.Batch(m.textarea.Cursor.BlinkCmd(), m.textarea.Focus())
+	return nil
 }
 
 func (m notesModel) Update(msg tea.Msg) (notesModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case contentViewPortHeightMsg:
 		m.width = msg.width
-		m.viewPort.Width = msg.width
-		m.viewPort.Height = msg.contentViewPortHeight
+		m.textarea.SetWidth(msg.width - 2)
+	case contentView:
+		m.active = msg == viewNotes
+		if m.active {
+			m.textarea.SetValue(m.player.Notes)
+
+			return m, m.textarea.Focus()
+		}
+
+		m.textarea.Blur()
 	case selectedPlayerMsg:
-		m.player = msg.player
-		m.textarea.SetValue(msg.notes)
+		// Don't discard any unsaved changes while editing.
+		if !m.active {
+			m.player = msg.player
+			m.player.Notes = msg.notes
+		}
+	case tea.KeyMsg:
+		if !m.active {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, defaultKeyMap.back):
+			return m, setContentView(viewMain)
+		case key.Matches(msg, defaultKeyMap.save):
+			if !m.player.SteamID.Valid() {
+				return m, setStatusMessage("No player selected", true)
+			}
+
+			m.player.Notes = m.textarea.Value()
+
+			return m, tea.Batch(
+				saveNotes(m.player.SteamID, m.player.Notes),
+				selectPlayer(m.player),
+				setStatusMessage("Saved notes for "+m.player.Name, false),
+				setContentView(viewMain))
+		}
+
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+
+		return m, cmd
 	}
 
-	return m, tea.Batch()
+	return m, nil
 }
 
 func (m notesModel) View(height int) string {
-	m.viewPort.SetContent(m.textarea.Value())
-	title := renderTitleBar(m.width, "Player Notes (doesnt work)")
-
-	m.viewPort.Height = height - lipgloss.Height(title)
+	title := renderTitleBar(m.width, "Player Notes: "+m.player.Name)
+	help := m.helpView.ShortHelpView([]key.Binding{defaultKeyMap.save, defaultKeyMap.back})
+	m.textarea.SetHeight(height - lipgloss.Height(title) - lipgloss.Height(help))
 
-	return lipgloss.JoinVertical(lipgloss.Top, title, m.textarea.View())
+	return lipgloss.JoinVertical(lipgloss.Top, title, m.textarea.View(), help)
 }
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
@@ -42,6 +43,14 @@ func (m detailPanelModel) Update(msg tea.Msg) (detailPanelModel, tea.Cmd) {
 		m.links = msg.Links
 	case Snapshot:
 		m.players = msg.Server.Players
+		// Keep the selected player fresh so that changes such as saved notes are reflected.
+		for _, player := range m.players {
+			if player.SteamID.Equal(m.player.SteamID) {
+				m.player = player
+
+				break
+			}
+		}
 	case contentViewPortHeightMsg:
 		m.width = msg.width
 		m.height = msg.height
@@ -138,6 +147,39 @@ func (m detailPanelModel) Render(height int) string {
 
 	rows = append(rows, styles.DetailRow("Friends (Steam)", strconv.Itoa(len(m.player.Friends))))
 
+	if len(m.player.MarkTags) > 0 {
+		marked := strings.Join(m.player.MarkTags, ", ")
+		if m.player.MarkReason != "" {
+			marked += " (" + m.player.MarkReason + ")"
+		}
+		rows = append(rows, styles.DetailRow("Marked", marked))
+	}
+
+	if m.player.Impersonates.Valid() {
+		impersonates := m.player.Impersonates.String()
+		if target, found := m.players.BySteamID(m.player.Impersonates); found {
+			impersonates = target.Name + " (" + impersonates + ")"
+		}
+		rows = append(rows, styles.DetailRow("Name Stealer", impersonates))
+	}
+
+	if m.player.VoteKicks > 0 {
+		rows = append(rows, styles.DetailRow("Vote Kicks", strconv.Itoa(m.player.VoteKicks)))
+	}
+
+	if m.player.Pending {
+		rows = append(rows, styles.DetailRow("Lobby", "Connecting"))
+	}
+
+	if m.player.KillsAgainst > 0 || m.player.KilledBy > 0 {
+		rows = append(rows, styles.DetailRow("Kills/Deaths vs. You",
+			fmt.Sprintf("%d/%d", m.player.KillsAgainst, m.player.KilledBy)))
+	}
+
+	if m.player.Notes != "" {
+		rows = append(rows, styles.DetailRow("Notes", m.player.Notes))
+	}
+
 	friends := m.players.FindFriends(m.player.SteamID)
 	rows = append(rows, styles.DetailRow("Friends (In Game)", strconv.Itoa(len(friends))))
 
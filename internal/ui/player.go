@@ -16,6 +16,7 @@ type Player struct {
 	Deaths                   int
 	Connected                bool
 	Team                     tf.Team
+	Class                    tf.PlayerClass
 	Alive                    bool
 	Health                   int
 	Valid                    bool
@@ -37,10 +38,51 @@ type Player struct {
 	Address                  string
 	Loss                     int
 	Time                     int
+	Notes                    string
+	KillsAgainst             int
+	KilledBy                 int
+	MarkTags                 []string
+	MarkReason               string
+	Impersonates             steamid.SteamID
+	VoteKicks                int
+	Pending                  bool
+}
+
+// onTeam reports whether the player is known to be playing on RED or BLU.
+func (p Player) onTeam() bool {
+	return p.Team == tf.RED || p.Team == tf.BLU
+}
+
+// tableTeam returns the team of the player table the player is listed in. Players who are not known to be
+// on a team, such as those who joined before we started in server mode and have not appeared in the logs
+// since, are split between both tables by their user id so that they are not hidden.
+func (p Player) tableTeam() tf.Team {
+	if p.onTeam() {
+		return p.Team
+	}
+
+	if p.UserID%2 == 0 {
+		return tf.RED
+	}
+
+	return tf.BLU
 }
 
 type Players []Player
 
+// NoTeamCount returns the number of players not known to be on RED or BLU.
+func (p Players) NoTeamCount() int {
+	var count int
+
+	for _, player := range p {
+		if !player.onTeam() {
+			count++
+		}
+	}
+
+	return count
+}
+
 func (p Players) TeamCount(team tf.Team) int {
 	var count int
 
@@ -53,6 +95,42 @@ func (p Players) TeamCount(team tf.Team) int {
 	return count
 }
 
+// ByName returns the player with the exact name. As names are not unique, no match is returned when
+// multiple players share the same name.
+func (p Players) ByName(name string) (Player, bool) {
+	found := p.AllByName(name)
+	if len(found) != 1 {
+		return Player{}, false
+	}
+
+	return found[0], true
+}
+
+// BySteamID returns the player with the matching steam id.
+func (p Players) BySteamID(steamID steamid.SteamID) (Player, bool) {
+	for _, player := range p {
+		if player.SteamID.Equal(steamID) {
+			return player, true
+		}
+	}
+
+	return Player{}, false
+}
+
+// AllByName returns all players using the exact name. More than one result means that multiple players
+// share the name, which is usually the result of someone impersonating another player.
+func (p Players) AllByName(name string) Players {
+	var found Players
+
+	for _, player := range p {
+		if player.Name == name {
+			found = append(found, player)
+		}
+	}
+
+	return found
+}
+
 // FindFriends searches through all players in the server for friend relationships. This means
 // as long as at least one of the friends has their friends list public it should link them.
 func (p Players) FindFriends(steamID steamid.SteamID) steamid.Collection {
@@ -31,15 +31,21 @@ type rootModel struct {
 	configModelModel       tea.Model
 	helpModel              tea.Model
 	notesModel             notesModel
+	markModel              markModel
+	voteKickModel          voteKickModel
 	tabsModel              tea.Model
 	statusModel            tea.Model
 	chatModel              chatModel
+	killFeedModel          killFeedModel
+	historyModel           historyModel
 	redTableModel          tea.Model
 	bluTableModel          tea.Model
 	footerHeight           int
 	headerHeight           int
 	serverMode             bool
-	parentContextChan      chan any
+	// replaying is set once the first ReplayStatus is received, enabling the playback controls.
+	replaying         bool
+	parentContextChan chan any
 }
 
 func newRootModel(userConfig config.Config, doSetup bool, buildVersion string, buildDate string, buildCommit string, loader ConfigWriter, cachePath string, parentChan chan any) *rootModel {
@@ -57,11 +63,15 @@ func newRootModel(userConfig config.Config, doSetup bool, buildVersion string, b
 		bdTableModel:           newTableBDModel(),
 		tabsModel:              newTabsModel(),
 		notesModel:             newNotesModel(),
+		markModel:              newMarkModel(),
+		voteKickModel:          newVoteKickModel(),
 		detailPanelModel:       newDetailPanelModel(userConfig.Links),
 		consoleModel:           newConsoleModel(),
 		serversTableModel:      newServerTableModel(),
 		statusModel:            newStatusBarModel(buildVersion, userConfig.ServerModeEnabled),
-		chatModel:              newChatModel(),
+		chatModel:              newChatModel(userConfig.ServerModeEnabled),
+		killFeedModel:          newKillFeedModel(),
+		historyModel:           newHistoryModel(),
 		serverDetailPanelModel: newServerDetailPanel(),
 		serverMode:             userConfig.ServerModeEnabled,
 		headerHeight:           1,
@@ -82,9 +92,13 @@ func (m rootModel) Init() tea.Cmd {
 		textinput.Blink,
 		m.tabsModel.Init(),
 		m.notesModel.Init(),
+		m.markModel.Init(),
+		m.voteKickModel.Init(),
 		m.consoleModel.Init(),
 		m.statusModel.Init(),
 		m.chatModel.Init(),
+		m.killFeedModel.Init(),
+		m.historyModel.Init(),
 		m.bdTableModel.Init(),
 		m.redTableModel.Init(),
 		m.bluTableModel.Init(),
@@ -103,6 +117,31 @@ func (m rootModel) Update(inMsg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The editors capture all key presses so that typing does not trigger any other bindings.
+	if keyMsg, ok := inMsg.(tea.KeyMsg); ok {
+		var cmd tea.Cmd
+		switch m.currentView { //nolint:exhaustive
+		case viewNotes:
+			m.notesModel, cmd = m.notesModel.Update(keyMsg)
+
+			return m, cmd
+		case viewMark:
+			m.markModel, cmd = m.markModel.Update(keyMsg)
+
+			return m, cmd
+		case viewVoteKick:
+			m.voteKickModel, cmd = m.voteKickModel.Update(keyMsg)
+
+			return m, cmd
+		case viewMain:
+			if m.chatModel.inputOpen {
+				m.chatModel, cmd = m.chatModel.Update(keyMsg)
+
+				return m, cmd
+			}
+		}
+	}
+
 	switch msg := inMsg.(type) {
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
@@ -133,6 +172,45 @@ func (m rootModel) Update(inMsg tea.Msg) (tea.Model, tea.Cmd) {
 				m.previousView = m.currentView
 				m.currentView = viewConfig
 			}
+		case key.Matches(msg, defaultKeyMap.notes):
+			if m.currentView == viewMain {
+				m.previousView = m.currentView
+				m.currentView = viewNotes
+
+				return m.propagate(m.currentView)
+			}
+		case key.Matches(msg, defaultKeyMap.mark):
+			if m.currentView == viewMain {
+				m.previousView = m.currentView
+				m.currentView = viewMark
+
+				return m.propagate(m.currentView)
+			}
+		case key.Matches(msg, defaultKeyMap.voteKick):
+			if m.currentView != viewMain {
+				break
+			}
+
+			if m.serverMode {
+				return m, setStatusMessage("Vote kicks are only available in client mode", true)
+			}
+
+			m.previousView = m.currentView
+			m.currentView = viewVoteKick
+
+			return m.propagate(m.currentView)
+		case key.Matches(msg, defaultKeyMap.exportMarks):
+			if m.currentView == viewMain {
+				return m, exportMarks()
+			}
+		case m.replaying && m.currentView == viewMain && key.Matches(msg, defaultKeyMap.replayPause):
+			return m, controlReplay(ReplayTogglePause)
+		case m.replaying && m.currentView == viewMain && key.Matches(msg, defaultKeyMap.replayBack):
+			return m, controlReplay(ReplaySeekBack)
+		case m.replaying && m.currentView == viewMain && key.Matches(msg, defaultKeyMap.replayForward):
+			return m, controlReplay(ReplaySeekForward)
+		case m.replaying && m.currentView == viewMain && key.Matches(msg, defaultKeyMap.replaySpeed):
+			return m, controlReplay(ReplayNextSpeed)
 		case key.Matches(msg, defaultKeyMap.left):
 			return m, selectTeam(tf.RED)
 
@@ -141,11 +219,27 @@ func (m rootModel) Update(inMsg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case contentView:
 		m.currentView = msg
+	case ReplayStatus:
+		m.replaying = true
+	case RCONCommand, SaveNotesRequest, MarkPlayerRequest, VoteKickRequest, ExportMarksRequest, MatchHistoryRequest,
+		MatchDetailRequest, ReplayRequest:
+		return m, m.sendParent(msg)
 	}
 
 	return m.propagate(inMsg)
 }
 
+// sendParent forwards requests that need to be handled outside the ui to the parent app.
+func (m rootModel) sendParent(msg tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		if m.parentContextChan != nil {
+			m.parentContextChan <- msg
+		}
+
+		return nil
+	}
+}
+
 func (m rootModel) View() string {
 	var (
 		header  string
@@ -172,6 +266,12 @@ func (m rootModel) View() string {
 		content = m.configModelModel.View()
 	case viewHelp:
 		content = m.helpModel.View()
+	case viewNotes:
+		content = m.notesModel.View(contentViewPortHeight)
+	case viewMark:
+		content = m.markModel.View(contentViewPortHeight)
+	case viewVoteKick:
+		content = m.voteKickModel.View(contentViewPortHeight)
 	case viewMain:
 		var upper string
 		if m.serverMode && m.activeTab == tabServers {
@@ -196,6 +296,10 @@ func (m rootModel) View() string {
 			lower = m.compTableModel.Render(lowerPanelViewportHeight)
 		case tabChat:
 			lower = m.chatModel.View(lowerPanelViewportHeight)
+		case tabKills:
+			lower = m.killFeedModel.View(lowerPanelViewportHeight)
+		case tabHistory:
+			lower = m.historyModel.Render(lowerPanelViewportHeight)
 		case tabConsole:
 			lower = m.consoleModel.Render(lowerPanelViewportHeight)
 		}
@@ -213,7 +317,7 @@ func (m rootModel) isInitialized() bool {
 }
 
 func (m rootModel) propagate(msg tea.Msg, _ ...tea.Cmd) (tea.Model, tea.Cmd) {
-	cmds := make([]tea.Cmd, 16)
+	cmds := make([]tea.Cmd, 20)
 
 	m.redTableModel, cmds[1] = m.redTableModel.Update(msg)
 	m.bluTableModel, cmds[2] = m.bluTableModel.Update(msg)
@@ -230,6 +334,10 @@ func (m rootModel) propagate(msg tea.Msg, _ ...tea.Cmd) (tea.Model, tea.Cmd) {
 	m.bdTableModel, cmds[13] = m.bdTableModel.Update(msg)
 	m.serversTableModel, cmds[14] = m.serversTableModel.Update(msg)
 	m.serverDetailPanelModel, cmds[15] = m.serverDetailPanelModel.Update(msg)
+	m.historyModel, cmds[16] = m.historyModel.Update(msg)
+	m.markModel, cmds[17] = m.markModel.Update(msg)
+	m.killFeedModel, cmds[18] = m.killFeedModel.Update(msg)
+	m.voteKickModel, cmds[19] = m.voteKickModel.Update(msg)
 
 	return m, tea.Batch(cmds...)
 }
@@ -2,9 +2,15 @@ package ui
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/leighmacdonald/steamid/v4/steamid"
 	"github.com/leighmacdonald/tf-tui/internal/tf"
 	"github.com/leighmacdonald/tf-tui/internal/tf/events"
 	"github.com/leighmacdonald/tf-tui/internal/ui/styles"
@@ -19,10 +25,13 @@ type statusBarModel struct {
 	snapshot    Snapshot
 	version     string
 	serverMode  bool
+	replay      *ReplayStatus
+	// imposters are the players that have already been warned about, mapped to the player being impersonated.
+	imposters map[steamid.SteamID]steamid.SteamID
 }
 
 func newStatusBarModel(version string, serverMode bool) *statusBarModel {
-	return &statusBarModel{version: version, serverMode: serverMode}
+	return &statusBarModel{version: version, serverMode: serverMode, imposters: map[steamid.SteamID]steamid.SteamID{}}
 }
 
 func (m statusBarModel) Init() tea.Cmd {
@@ -33,14 +42,31 @@ func (m statusBarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case selectServerSnapshotMsg:
 		m.snapshot = msg.server
-	case statusMsg:
+	case StatusMsg:
 		m.statusMsg = msg.Message
 		m.statusError = msg.Err
 
 		return m, clearErrorAfter(clearMessageTimeout)
+	case AlertMsg:
+		var cmds []tea.Cmd
+		if msg.Message != "" {
+			m.statusMsg = msg.Message
+			m.statusError = true
+			cmds = append(cmds, clearErrorAfter(clearMessageTimeout))
+		}
+
+		if msg.Bell {
+			cmds = append(cmds, ringBell)
+		}
+
+		return m, tea.Batch(cmds...)
+	case ReplayStatus:
+		m.replay = &msg
 	case clearStatusMessageMsg:
 		m.statusError = false
 		m.statusMsg = ""
+	case Snapshot:
+		return m, m.checkImposters(msg.Server.Players)
 	case contentViewPortHeightMsg:
 		m.width = msg.width
 	case events.Event:
@@ -55,12 +81,61 @@ func (m statusBarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// checkImposters shows a warning for any players that have newly started using the name of another player.
+func (m statusBarModel) checkImposters(players Players) tea.Cmd {
+	var warnings []string
+
+	current := map[steamid.SteamID]steamid.SteamID{}
+	for _, player := range players {
+		if !player.Impersonates.Valid() {
+			continue
+		}
+
+		current[player.SteamID] = player.Impersonates
+		if previous, found := m.imposters[player.SteamID]; found && previous.Equal(player.Impersonates) {
+			continue
+		}
+
+		target := player.Impersonates.String()
+		if original, found := players.BySteamID(player.Impersonates); found {
+			target = original.Name
+		}
+
+		warnings = append(warnings, fmt.Sprintf("%s Name stealer: %s (%s) is impersonating %s",
+			styles.IconImposter, player.Name, player.SteamID.String(), target))
+	}
+
+	maps.DeleteFunc(m.imposters, func(steamID steamid.SteamID, _ steamid.SteamID) bool {
+		_, found := current[steamID]
+
+		return !found
+	})
+	maps.Copy(m.imposters, current)
+
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	return setStatusMessage(strings.Join(warnings, " | "), true)
+}
+
+// ringBell writes the bell character to stderr, which is a separate stream from the one being rendered to,
+// so it cannot be interleaved with the output of a render.
+func ringBell() tea.Msg {
+	_, _ = os.Stderr.WriteString("\a")
+
+	return nil
+}
+
 func (m statusBarModel) View() string {
 	var args []string
 	if !m.serverMode {
 		args = append(args,
 			styles.StatusRedTeam.Render(fmt.Sprintf("%3d", m.snapshot.Server.Players.TeamCount(tf.RED))),
 			styles.StatusBluTeam.Render(fmt.Sprintf("%3d", m.snapshot.Server.Players.TeamCount(tf.BLU))))
+		if noTeam := m.snapshot.Server.Players.NoTeamCount(); noTeam > 0 {
+			args = append(args, styles.StatusMessage.Render(fmt.Sprintf("%s %d", styles.IconNoTeam, noTeam)))
+		}
 	} else {
 		if m.snapshot.Status.Stats.FPS < 66 {
 			args = append(args, styles.StatusError.Underline(true).Render(fmt.Sprintf("FPS %2.2f", m.snapshot.Status.Stats.FPS)))
@@ -74,6 +149,9 @@ func (m statusBarModel) View() string {
 			styles.StatusMap.Render("steam://run/440//+connect%20"+m.snapshot.HostPort),
 		)
 	}
+	if m.replay != nil {
+		args = append(args, styles.StatusMessage.Render(m.replayStatus()))
+	}
 	args = append(args,
 		styles.StatusVersion.Render(m.version),
 		styles.StatusHelp.Render(fmt.Sprintf("%s %s", defaultKeyMap.help.Help().Key, defaultKeyMap.help.Help().Desc)),
@@ -83,6 +161,25 @@ func (m statusBarModel) View() string {
 	return lipgloss.NewStyle().Width(m.width).Background(styles.Black).Render(lipgloss.JoinHorizontal(lipgloss.Top, args...))
 }
 
+// replayStatus renders the playback state of replayed logs, eg: "▶ 10x 01:25:53/02:10:00".
+func (m statusBarModel) replayStatus() string {
+	state := "▶"
+	switch {
+	case m.replay.Done:
+		state = "■"
+	case m.replay.Paused:
+		state = "⏸"
+	}
+
+	speed := "max"
+	if m.replay.Speed > 0 {
+		speed = strconv.FormatFloat(m.replay.Speed, 'f', -1, 64) + "x"
+	}
+
+	return fmt.Sprintf("%s %s %s/%s", state, speed,
+		m.replay.Position.Format(time.TimeOnly), m.replay.End.Format(time.TimeOnly))
+}
+
 func (m statusBarModel) status() string {
 	if m.statusMsg != "" {
 		if m.statusError {
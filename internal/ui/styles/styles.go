@@ -55,6 +55,9 @@ var (
 	PlayerTableRow     = lipgloss.NewStyle().Foreground(White)
 	PlayerTableRowOdd  = lipgloss.NewStyle().Foreground(Whiter)
 	PlayerTableRowSelf = lipgloss.NewStyle().Foreground(ColourGenuine)
+	PlayerTableRowMark = lipgloss.NewStyle().Foreground(Accent).Bold(true)
+	// PlayerTableRowImposter is used for players copying the name of another player.
+	PlayerTableRowImposter = lipgloss.NewStyle().Foreground(ColourLimited).Bold(true)
 
 	ConsoleTime       = lipgloss.NewStyle().Foreground(Gray).Background(Black)
 	ConsoleOther      = lipgloss.NewStyle().Foreground(ColourVintage)
@@ -104,20 +107,27 @@ var (
 	HelpBox = lipgloss.NewStyle().Padding(3)
 
 	// 🚨 👮 💂 🕵️ 👷 🐈 🏟️ 🪵 ♻️.
-	IconServers = "🌍"
-	IconPlayers = "👥"
-	IconDead    = "💀"
-	IconComp    = "🏁"
-	IconCheck   = "✅"
-	IconBans    = "🛑"
-	IconVac     = "👮"
-	// IconNotes   = "📓".
-	IconInfo    = "💡"
-	IconChat    = "🌮"
-	IconConsole = "🐤"
-	IconNoBans  = "🍕"
-	IconNoComp  = "🍣"
-	IconBD      = "🕵️"
+	IconServers  = "🌍"
+	IconPlayers  = "👥"
+	IconDead     = "💀"
+	IconComp     = "🏁"
+	IconCheck    = "✅"
+	IconBans     = "🛑"
+	IconVac      = "👮"
+	IconNotes    = "📓"
+	IconMarked   = "🚩"
+	IconKill     = "🔫"
+	IconInfo     = "💡"
+	IconChat     = "🌮"
+	IconConsole  = "🐤"
+	IconHistory  = "📜"
+	IconNoBans   = "🍕"
+	IconNoComp   = "🍣"
+	IconBD       = "🕵️"
+	IconWarning  = "⚠️"
+	IconImposter = "🎭"
+	IconPending  = "⏳"
+	IconNoTeam   = "❔"
 )
 
 func DetailRow(label string, value string) string {
@@ -35,6 +35,8 @@ const (
 	colAddress
 	colLoss
 	colTime
+	colNotes
+	colClass
 )
 
 // playerTableColSize defines the sizes of the player columns.
@@ -50,6 +52,8 @@ const (
 	colAddressSize playerTableColSize = 15
 	colLossSize    playerTableColSize = 5
 	colTimeSize    playerTableColSize = 5
+	colNotesSize   playerTableColSize = 4
+	colClassSize   playerTableColSize = 12
 )
 
 func newPlayerTableModel(team tf.Team, selfSID steamid.SteamID, serverMode bool) *tablePlayerModel {
@@ -132,7 +136,7 @@ func (m *tablePlayerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-			for _, markID := range []string{"name", "uid", "score", "meta", "deaths", "ping", "address", "loss", "time"} {
+			for _, markID := range []string{"name", "uid", "score", "meta", "deaths", "ping", "address", "loss", "time", "notes", "class"} {
 				if zone.Get(m.id + markID).InBounds(msg) {
 					var col playerTableCol
 					switch markID {
@@ -152,6 +156,10 @@ func (m *tablePlayerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						col = colLoss
 					case "time":
 						col = colTime
+					case "notes":
+						col = colNotes
+					case "class":
+						col = colClass
 					default:
 						col = colName
 					}
@@ -313,6 +321,10 @@ func (m *tablePlayerModel) View() string {
 				width = colLossSize
 			case colTime:
 				width = colTimeSize
+			case colNotes:
+				width = colNotesSize
+			case colClass:
+				width = colClassSize
 			}
 			switch {
 			case row == table.HeaderRow:
@@ -350,6 +362,10 @@ func (m *tablePlayerModel) View() string {
 				}
 
 				return styles.SelectedCellStyleBlu.Width(int(width))
+			case row >= 0 && row < len(m.data.players) && len(m.data.players[row].MarkTags) > 0:
+				return styles.PlayerTableRowMark.Width(int(width))
+			case row >= 0 && row < len(m.data.players) && m.data.players[row].Impersonates.Valid():
+				return styles.PlayerTableRowImposter.Width(int(width))
 			case playerTableCol(col) == colName:
 				return styles.PlayerTableRow.Width(int(width))
 			case row%2 == 0:
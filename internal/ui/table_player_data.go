@@ -13,8 +13,8 @@ import (
 )
 
 var (
-	defaultLocalColumns  = []playerTableCol{colMeta, colName, colScore, colDeaths, colPing}
-	defaultServerColumns = []playerTableCol{colMeta, colName, colLoss, colPing, colAddress}
+	defaultLocalColumns  = []playerTableCol{colMeta, colNotes, colName, colScore, colDeaths, colPing}
+	defaultServerColumns = []playerTableCol{colMeta, colNotes, colName, colClass, colLoss, colPing, colAddress}
 )
 
 func newTablePlayerData(parentZoneID string, serverMode bool, playersUpdate Players, team tf.Team, cols ...playerTableCol) *tablePlayerData {
@@ -41,7 +41,7 @@ func newTablePlayerData(parentZoneID string, serverMode bool, playersUpdate Play
 		if !player.SteamID.Valid() {
 			continue
 		}
-		if player.Team != team {
+		if player.tableTeam() != team {
 			continue
 		}
 
@@ -84,6 +84,10 @@ func (m *tablePlayerData) Headers() []string {
 			headers = append(headers, zone.Mark(m.zoneID+"loss", "Loss"))
 		case colTime:
 			headers = append(headers, zone.Mark(m.zoneID+"time", "Time"))
+		case colNotes:
+			headers = append(headers, zone.Mark(m.zoneID+"notes", "Note"))
+		case colClass:
+			headers = append(headers, zone.Mark(m.zoneID+"class", "Class"))
 		}
 	}
 
@@ -113,6 +117,10 @@ func (m *tablePlayerData) Sort(column playerTableCol, asc bool) {
 			return cmp.Compare(a.Loss, b.Loss)
 		case colTime:
 			return cmp.Compare(a.Time, b.Time)
+		case colNotes:
+			return cmp.Compare(len(a.Notes), len(b.Notes))
+		case colClass:
+			return cmp.Compare(a.Class, b.Class)
 		case colMeta:
 			av := len(a.Bans) + int(a.NumberOfVacBans)
 			bv := len(b.Bans) + int(b.NumberOfVacBans)
@@ -169,6 +177,14 @@ func (m *tablePlayerData) At(row int, col int) string {
 		return strconv.Itoa(player.Loss)
 	case colTime:
 		return strconv.Itoa(player.Time)
+	case colNotes:
+		if player.Notes != "" {
+			return styles.IconNotes
+		}
+
+		return ""
+	case colClass:
+		return player.Class.String()
 	}
 
 	return "?"
@@ -192,6 +208,22 @@ func (m *tablePlayerData) metaColumn(player Player) string {
 		afflictions = append(afflictions, styles.IconVac)
 	}
 
+	if len(player.MarkTags) > 0 {
+		afflictions = append(afflictions, styles.IconMarked)
+	}
+
+	if player.Impersonates.Valid() {
+		afflictions = append(afflictions, styles.IconImposter)
+	}
+
+	if player.Pending {
+		afflictions = append(afflictions, styles.IconPending)
+	}
+
+	if !player.onTeam() {
+		afflictions = append(afflictions, styles.IconNoTeam)
+	}
+
 	// if len(afflictions) == 0 {
 	//	afflictions = append(afflictions, styles.IconCheck)
 	//}
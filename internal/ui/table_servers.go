@@ -23,6 +23,7 @@ const (
 	colServerInRate
 	colServerOutRate
 	colServerConnects
+	colServerRCON
 )
 
 type serverTableColSize int
@@ -39,6 +40,7 @@ const (
 	colServerInRateSize   serverTableColSize = 12
 	colServerOutRateSize  serverTableColSize = 12
 	colServerConnectsSize serverTableColSize = 6
+	colServerRCONSize     serverTableColSize = 10
 )
 
 var defaultServerTableColumns = []serverTableCol{
@@ -53,6 +55,7 @@ var defaultServerTableColumns = []serverTableCol{
 	colServerInRate,
 	colServerOutRate,
 	colServerConnects,
+	colServerRCON,
 }
 
 func newServerTableModel() *serverTableModel {
@@ -118,7 +121,7 @@ func (m *serverTableModel) Update(msg tea.Msg) (*serverTableModel, tea.Cmd) {
 			}
 		}
 
-		for _, markID := range []string{"n", "m", "r", "pl", "pi", "u", "cp", "f", "i", "o", "co"} {
+		for _, markID := range []string{"n", "m", "r", "pl", "pi", "u", "cp", "f", "i", "o", "co", "rcon"} {
 			if zone.Get(m.zoneID + markID).InBounds(msg) {
 				var col serverTableCol
 				switch markID {
@@ -144,6 +147,8 @@ func (m *serverTableModel) Update(msg tea.Msg) (*serverTableModel, tea.Cmd) {
 					col = colServerOutRate
 				case "co":
 					col = colServerConnects
+				case "rcon":
+					col = colServerRCON
 				}
 
 				m.data.Sort(col, !m.data.asc)
@@ -191,6 +196,8 @@ func (m *serverTableModel) View() string {
 				width = colServerOutRateSize
 			case colServerConnects:
 				width = colServerConnectsSize
+			case colServerRCON:
+				width = colServerRCONSize
 			}
 
 			switch {
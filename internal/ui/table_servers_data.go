@@ -64,6 +64,8 @@ func (m *serverTableData) Headers() []string {
 			headers = append(headers, zone.Mark(m.zoneID+"rate_out", "Out KB/s"))
 		case colServerConnects:
 			headers = append(headers, zone.Mark(m.zoneID+"conns", "Conns"))
+		case colServerRCON:
+			headers = append(headers, zone.Mark(m.zoneID+"rcon", "RCON"))
 		}
 	}
 
@@ -98,6 +100,8 @@ func (m *serverTableData) Sort(column serverTableCol, asc bool) {
 			return cmp.Compare(a.Status.Stats.OutKBs, b.Status.Stats.OutKBs)
 		case colServerConnects:
 			return cmp.Compare(a.Status.Stats.Connects, b.Status.Stats.Connects)
+		case colServerRCON:
+			return cmp.Compare(a.RCON.Latency, b.RCON.Latency)
 		default:
 			return 0
 		}
@@ -167,6 +171,16 @@ func (m *serverTableData) At(row int, col int) string {
 		uptime := time.Duration(snapshot.Status.Stats.Uptime) * time.Second
 
 		return uptime.String()
+	case colServerRCON:
+		if !snapshot.RCON.Connected {
+			if snapshot.RCON.Failures == 0 {
+				return ""
+			}
+
+			return fmt.Sprintf("down (%d)", snapshot.RCON.Failures)
+		}
+
+		return snapshot.RCON.Latency.Round(time.Millisecond).String()
 	}
 
 	return "?"
@@ -17,6 +17,8 @@ const (
 	tabBD
 	tabComp
 	tabChat
+	tabKills
+	tabHistory
 	tabConsole
 )
 
@@ -59,6 +61,16 @@ func newTabsModel() tea.Model {
 				tab:    tabChat,
 				zoneID: zone.NewPrefix(),
 			},
+			{
+				label:  styles.IconKill + " Kills",
+				tab:    tabKills,
+				zoneID: zone.NewPrefix(),
+			},
+			{
+				label:  styles.IconHistory + " History",
+				tab:    tabHistory,
+				zoneID: zone.NewPrefix(),
+			},
 			{
 				label:  styles.IconConsole + " Console",
 				tab:    tabConsole,
@@ -127,6 +139,12 @@ func (m tabsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, defaultKeyMap.chat):
 			m.selectedTab = tabChat
 			changed = true
+		case key.Matches(msg, defaultKeyMap.kills):
+			m.selectedTab = tabKills
+			changed = true
+		case key.Matches(msg, defaultKeyMap.history):
+			m.selectedTab = tabHistory
+			changed = true
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/leighmacdonald/tf-tui/internal/config"
 	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/tf/rcon"
 	zone "github.com/lrstanley/bubblezone"
 )
 
@@ -23,6 +24,9 @@ const (
 	viewMain contentView = iota
 	viewConfig
 	viewHelp
+	viewNotes
+	viewMark
+	viewVoteKick
 )
 
 type Snapshot struct {
@@ -33,6 +37,8 @@ type Snapshot struct {
 	PluginsSM   []tf.GamePlugin
 	PluginsMeta []tf.GamePlugin
 	CVars       tf.CVarList
+	// RCON is the health of the persistent rcon connection to the server.
+	RCON rcon.Health
 }
 
 func (s Snapshot) AvgPing() float64 {
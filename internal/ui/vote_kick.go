@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/leighmacdonald/tf-tui/internal/tf"
+	"github.com/leighmacdonald/tf-tui/internal/ui/styles"
+)
+
+// kickReasons are the reasons accepted by the callvote kick command.
+var kickReasons = []tf.KickReason{ //nolint:gochecknoglobals
+	tf.KickReasonCheating, tf.KickReasonIdle, tf.KickReasonScamming, tf.KickReasonOther,
+}
+
+// voteKickModel is used to pick a reason and confirm calling a vote to kick the currently selected player.
+type voteKickModel struct {
+	player     Player
+	cursor     int
+	confirming bool
+	helpView   help.Model
+	active     bool
+	width      int
+}
+
+func newVoteKickModel() voteKickModel {
+	return voteKickModel{helpView: help.New()}
+}
+
+func (m voteKickModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m voteKickModel) Update(msg tea.Msg) (voteKickModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case contentViewPortHeightMsg:
+		m.width = msg.width
+	case contentView:
+		m.active = msg == viewVoteKick
+		m.cursor = 0
+		m.confirming = false
+	case selectedPlayerMsg:
+		if !m.active {
+			m.player = msg.player
+		}
+	case tea.KeyMsg:
+		if !m.active {
+			break
+		}
+
+		return m.onKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m voteKickModel) onKey(msg tea.KeyMsg) (voteKickModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, defaultKeyMap.back):
+		if m.confirming {
+			m.confirming = false
+
+			return m, nil
+		}
+
+		return m, setContentView(viewMain)
+	case key.Matches(msg, defaultKeyMap.accept):
+		if !m.confirming {
+			m.confirming = true
+
+			return m, nil
+		}
+
+		if !m.player.SteamID.Valid() || m.player.UserID <= 0 {
+			return m, tea.Batch(
+				setStatusMessage("Cannot vote kick, unknown player", true),
+				setContentView(viewMain))
+		}
+
+		return m, tea.Batch(
+			voteKick(m.player.SteamID, m.player.UserID, kickReasons[m.cursor]),
+			setStatusMessage(fmt.Sprintf("Calling vote kick against %s (%s)", m.player.Name, kickReasons[m.cursor]), false),
+			setContentView(viewMain))
+	case m.confirming:
+		break
+	case key.Matches(msg, defaultKeyMap.up):
+		m.cursor = max(0, m.cursor-1)
+	case key.Matches(msg, defaultKeyMap.down):
+		m.cursor = min(len(kickReasons)-1, m.cursor+1)
+	}
+
+	return m, nil
+}
+
+func (m voteKickModel) View(height int) string {
+	title := renderTitleBar(m.width, "Vote Kick: "+m.player.Name)
+
+	rows := []string{title, ""}
+	if m.confirming {
+		rows = append(rows,
+			styles.FocusedStyle.Render(fmt.Sprintf("Call a vote to kick %s (#%d) for %s?",
+				m.player.Name, m.player.UserID, kickReasons[m.cursor])),
+			"",
+			m.helpView.ShortHelpView([]key.Binding{defaultKeyMap.accept, defaultKeyMap.back}))
+
+		return lipgloss.NewStyle().Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	}
+
+	for idx, reason := range kickReasons {
+		style := styles.BlurredStyle
+		prefix := "  "
+		if idx == m.cursor {
+			style = styles.FocusedStyle
+			prefix = "> "
+		}
+
+		rows = append(rows, style.Render(prefix+string(reason)))
+	}
+
+	if m.player.VoteKicks > 0 {
+		rows = append(rows, "", styles.HelpStyle.Render(fmt.Sprintf("Previous vote kicks: %d", m.player.VoteKicks)))
+	}
+
+	rows = append(rows, "", m.helpView.ShortHelpView([]key.Binding{
+		defaultKeyMap.up, defaultKeyMap.down, defaultKeyMap.accept, defaultKeyMap.back,
+	}))
+
+	return lipgloss.NewStyle().Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}